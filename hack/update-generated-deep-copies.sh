@@ -14,6 +14,10 @@ function codegen::join() { local IFS="$1"; shift; echo "$*"; }
 # enumerate group versions
 ALL_FQ_APIS=(
     github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth
+    github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1
+    github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewbatch/v1
+    github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewdiagnostic/v1
+    github.com/openshift/oauth-apiserver/pkg/oauth/apis/useroauthaccesstokenrequest/v1
     github.com/openshift/oauth-apiserver/pkg/user/apis/user
 )
 
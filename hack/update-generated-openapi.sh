@@ -31,6 +31,12 @@ ORIGIN_INPUT_DIRS=(
     sort -u
   )
 )
+LOCAL_INPUT_DIRS=(
+    ${ORIGIN_PREFIX}pkg/oauth/apis/sessionpolicy/v1
+    ${ORIGIN_PREFIX}pkg/oauth/apis/tokenreviewbatch/v1
+    ${ORIGIN_PREFIX}pkg/oauth/apis/tokenreviewdiagnostic/v1
+    ${ORIGIN_PREFIX}pkg/oauth/apis/useroauthaccesstokenrequest/v1
+)
 APIEXTENSIONS_INPUT_DIRS=(
     k8s.io/apimachinery/pkg/apis/meta/v1
     k8s.io/api/autoscaling/v1
@@ -40,7 +46,7 @@ function join { local IFS="$1"; shift; echo "$*"; }
 
 echo "Generating origin openapi"
 ${GOPATH}/bin/openapi-gen \
-  "${KUBE_INPUT_DIRS[@]}" "${ORIGIN_INPUT_DIRS[@]}" \
+  "${KUBE_INPUT_DIRS[@]}" "${ORIGIN_INPUT_DIRS[@]}" "${LOCAL_INPUT_DIRS[@]}" \
   --output-file zz_generated.openapi.go \
   --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.txt \
   --output-dir="${SCRIPT_ROOT}/pkg/openapi" \
@@ -15,7 +15,11 @@ import (
 
 	openshiftcontrolplanev1 "github.com/openshift/api/openshiftcontrolplane/v1"
 	oauthapiserver "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+	tokenreviews "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviews"
 	"github.com/openshift/oauth-apiserver/pkg/serverscheme"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/jwtaccesstoken"
 	userapiserver "github.com/openshift/oauth-apiserver/pkg/user/apiserver"
 	"github.com/openshift/oauth-apiserver/pkg/version"
 )
@@ -30,7 +34,27 @@ type OAuthAPIExtraConfig struct {
 	// AccessTokenInactivityTimeout is a time period after which an oauthaccesstoken
 	// is considered invalid unless it gets used again
 	AccessTokenInactivityTimeout time.Duration
-	APIAudiences                 authenticator.Audiences
+	// AbsoluteSessionLifetime is a time period after the original authorization after
+	// which all oauthaccesstokens of a session are considered invalid
+	AbsoluteSessionLifetime time.Duration
+	APIAudiences            authenticator.Audiences
+	// DisableBootstrapAuthenticator turns off authentication of the kube:admin bootstrap user
+	DisableBootstrapAuthenticator bool
+	// BootstrapUserRotationGracePeriod is a time period after a change of the kubeadmin
+	// secret during which tokens issued against the previous secret are still accepted
+	BootstrapUserRotationGracePeriod time.Duration
+	// JWTAccessTokens enables the authentication of signed JWT access tokens if set
+	JWTAccessTokens *jwtaccesstoken.Config
+	// TokenValidators is the chain of token validators, the default chain if nil
+	TokenValidators []tokenvalidation.ValidatorConfig
+	// TokenReviewFailureLimits limits the failed token reviews per requesting user and forwarded client
+	TokenReviewFailureLimits tokenreviews.FailureLimits
+	// PersonalAccessTokenMaxLifetime caps the lifetime of the tokens users create themselves
+	PersonalAccessTokenMaxLifetime time.Duration
+	// AccessTokenQuota limits the live access tokens per user and per user and client
+	AccessTokenQuota oauthaccesstoken.Quota
+	// AccessTokenLifetimeLimit limits the lifetime of all new access tokens
+	AccessTokenLifetimeLimit oauthaccesstoken.LifetimeLimit
 }
 
 type OAuthAPIServer struct {
@@ -107,7 +131,17 @@ func (c *completedConfig) withOAuthAPIServer(delegateAPIServer genericapiserver.
 			ServiceAccountMethod: string(openshiftcontrolplanev1.GrantHandlerPrompt),
 
 			AccessTokenInactivityTimeout: c.ExtraConfig.AccessTokenInactivityTimeout,
+			AbsoluteSessionLifetime:      c.ExtraConfig.AbsoluteSessionLifetime,
 			ImplicitAudiences:            c.ExtraConfig.APIAudiences,
+
+			DisableBootstrapAuthenticator:    c.ExtraConfig.DisableBootstrapAuthenticator,
+			BootstrapUserRotationGracePeriod: c.ExtraConfig.BootstrapUserRotationGracePeriod,
+			JWTAccessTokens:                  c.ExtraConfig.JWTAccessTokens,
+			TokenValidators:                  c.ExtraConfig.TokenValidators,
+			TokenReviewFailureLimits:         c.ExtraConfig.TokenReviewFailureLimits,
+			PersonalAccessTokenMaxLifetime:   c.ExtraConfig.PersonalAccessTokenMaxLifetime,
+			AccessTokenQuota:                 c.ExtraConfig.AccessTokenQuota,
+			AccessTokenLifetimeLimit:         c.ExtraConfig.AccessTokenLifetimeLimit,
 		},
 	}
 	// server is required to install OpenAPI to register and serve openapi spec for its types
@@ -26,16 +26,17 @@ func (tokenReviewAuthorizer) Authorize(ctx context.Context, a authorizer.Attribu
 	if a.IsResourceRequest() &&
 		a.GetVerb() == "create" &&
 		a.GetAPIGroup() == "oauth.openshift.io" &&
-		a.GetResource() == "tokenreviews" &&
+		(a.GetResource() == "tokenreviews" || a.GetResource() == "tokenreviewbatches") &&
 		len(a.GetSubresource()) == 0 &&
 		len(a.GetNamespace()) == 0 {
-		return authorizer.DecisionAllow, "requesting tokenreviews is allowed", nil
+		return authorizer.DecisionAllow, "requesting " + a.GetResource() + " is allowed", nil
 	}
 
 	return authorizer.DecisionNoOpinion, "", nil
 }
 
-// NewHardCodedTokenReviewAuthorizer returns an authorizer that allows the expected kube-apiserver user to run tokenreviews.
+// NewHardCodedTokenReviewAuthorizer returns an authorizer that allows the expected kube-apiserver user to run tokenreviews
+// and tokenreviewbatches.
 func NewHardCodedTokenReviewAuthorizer() *tokenReviewAuthorizer {
 	return new(tokenReviewAuthorizer)
 }
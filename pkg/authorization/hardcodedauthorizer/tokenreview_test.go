@@ -23,6 +23,9 @@ func TestAuthorizer(t *testing.T) {
 				authorizer.AttributesRecord{
 					User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-oauth-apiserver:openshift-authenticator"},
 					Verb: "create", APIGroup: "oauth.openshift.io", Resource: "tokenreviews", Subresource: "", ResourceRequest: true},
+				authorizer.AttributesRecord{
+					User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-oauth-apiserver:openshift-authenticator"},
+					Verb: "create", APIGroup: "oauth.openshift.io", Resource: "tokenreviewbatches", Subresource: "", ResourceRequest: true},
 			},
 			shouldNoOpinion: []authorizer.Attributes{
 				// wrong user
@@ -41,6 +44,10 @@ func TestAuthorizer(t *testing.T) {
 				authorizer.AttributesRecord{
 					User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-oauth-apiserver:openshift-authenticator"},
 					Verb: "get", APIGroup: "oauth.openshift.io", Resource: "other-resource", Subresource: "", ResourceRequest: true},
+				// diagnostics must be granted explicitly
+				authorizer.AttributesRecord{
+					User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-oauth-apiserver:openshift-authenticator"},
+					Verb: "create", APIGroup: "oauth.openshift.io", Resource: "tokenreviewdiagnostics", Subresource: "", ResourceRequest: true},
 				// wrong subresource
 				authorizer.AttributesRecord{
 					User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-oauth-apiserver:openshift-authenticator"},
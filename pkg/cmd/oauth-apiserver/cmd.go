@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 
 	apifeatures "github.com/openshift/api/features"
 	"github.com/openshift/library-go/pkg/features"
@@ -12,10 +13,17 @@ import (
 	"github.com/openshift/oauth-apiserver/pkg/apiserver"
 	"github.com/openshift/oauth-apiserver/pkg/authorization/hardcodedauthorizer"
 	"github.com/openshift/oauth-apiserver/pkg/cmd/oauth-apiserver/openapiconfig"
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/clientauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/introspection"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/revocation"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/userinfo"
 	"github.com/openshift/oauth-apiserver/pkg/serverscheme"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/jwtaccesstoken"
 	tokenvalidationoptions "github.com/openshift/oauth-apiserver/pkg/tokenvalidation/options"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/authorization/union"
@@ -26,6 +34,7 @@ import (
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/featuregate"
+	"k8s.io/component-base/tracing"
 
 	// register api groups
 	_ "github.com/openshift/oauth-apiserver/pkg/api/install"
@@ -59,6 +68,16 @@ func NewOAuthAPIServerOptions(out io.Writer, featureGate featuregate.MutableFeat
 		FeatureGateOptions:     features.NewFeatureGateOptionsOrDie(featureGate, apifeatures.SelfManaged),
 		Output:                 out,
 	}
+	// the JWT access token verification keys must be available to anyone verifying the tokens,
+	// the userinfo endpoint checks the scopes of the token itself and
+	// the introspection and revocation endpoints authenticate OAuth clients themselves
+	o.RecommendedOptions.Authorization.AlwaysAllowPaths = append(o.RecommendedOptions.Authorization.AlwaysAllowPaths,
+		jwtaccesstoken.DiscoveryPath,
+		jwtaccesstoken.JWKSPath,
+		userinfo.Path,
+		introspection.Path,
+		revocation.Path,
+	)
 	return o
 }
 
@@ -164,6 +183,11 @@ func (o *OAuthAPIServerOptions) NewOAuthAPIServerConfig() (*apiserver.Config, er
 	if err := o.RecommendedOptions.ApplyTo(serverConfig.GenericConfig); err != nil {
 		return nil, err
 	}
+	// the tracing options are applied before the loopback client config exists, so the
+	// loopback clients used to validate tokens are not traced unless we wrap them here
+	if o.RecommendedOptions.Traces != nil && len(o.RecommendedOptions.Traces.ConfigFile) > 0 {
+		serverConfig.GenericConfig.LoopbackClientConfig.Wrap(tracing.WrapperFor(serverConfig.GenericConfig.TracerProvider))
+	}
 
 	// the oauth-apiserver provides an autentication webhook.  To avoid cyclical authorization checks, we will hardcode
 	// the expected user to a tokenreview permission.  Since this rule could never logically be removed in an openshift
@@ -173,6 +197,12 @@ func (o *OAuthAPIServerOptions) NewOAuthAPIServerConfig() (*apiserver.Config, er
 		serverConfig.GenericConfig.Authorization.Authorizer,
 	)
 
+	// authentication removes the Authorization header, but the userinfo and introspection endpoints
+	// need to validate the credentials in it themselves
+	serverConfig.GenericConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return clientauth.WithClientCredentials(userinfo.WithBearerToken(genericapiserver.DefaultBuildHandlerChain(apiHandler, c)), introspection.Path, revocation.Path)
+	}
+
 	// the following section overwrites RESTOptionsGetter
 	// note we don't call ApplyWithStorageFactoryTo explicitly to prevent double registration of storage related health checks
 	o.RecommendedOptions.Etcd.DefaultStorageMediaType = "application/vnd.kubernetes.protobuf"
@@ -184,6 +214,8 @@ func (o *OAuthAPIServerOptions) NewOAuthAPIServerConfig() (*apiserver.Config, er
 		&apiserverstorage.ResourceConfig{},
 		specialDefaultResourcePrefixes,
 	)
+	// session policies are defined locally and have no protobuf serialization
+	storageFactory.SetSerializer(sessionpolicyv1.Resource("sessionpolicies"), runtime.ContentTypeJSON, nil)
 
 	// ApplyTo was called already which set up etcd health endpoints
 	o.RecommendedOptions.Etcd.SkipHealthEndpoints = true
@@ -193,7 +225,25 @@ func (o *OAuthAPIServerOptions) NewOAuthAPIServerConfig() (*apiserver.Config, er
 	}
 
 	serverConfig.ExtraConfig.AccessTokenInactivityTimeout = o.TokenValidationOptions.AccessTokenInactivityTimeout
+	serverConfig.ExtraConfig.AbsoluteSessionLifetime = o.TokenValidationOptions.AbsoluteSessionLifetime
 	serverConfig.ExtraConfig.APIAudiences = o.TokenValidationOptions.APIAudiences
+	serverConfig.ExtraConfig.DisableBootstrapAuthenticator = o.TokenValidationOptions.DisableBootstrapAuthenticator
+	serverConfig.ExtraConfig.BootstrapUserRotationGracePeriod = o.TokenValidationOptions.BootstrapUserRotationGracePeriod
+	serverConfig.ExtraConfig.TokenReviewFailureLimits = o.TokenValidationOptions.TokenReviewFailureLimits()
+	serverConfig.ExtraConfig.PersonalAccessTokenMaxLifetime = o.TokenValidationOptions.PersonalAccessTokenMaxLifetime
+	serverConfig.ExtraConfig.AccessTokenQuota = o.TokenValidationOptions.AccessTokenQuota()
+	serverConfig.ExtraConfig.AccessTokenLifetimeLimit = o.TokenValidationOptions.AccessTokenLifetimeLimit()
+	serverConfig.ExtraConfig.TokenValidators, err = o.TokenValidationOptions.TokenValidatorConfigs()
+	if err != nil {
+		return nil, err
+	}
+	serverConfig.ExtraConfig.JWTAccessTokens, err = o.TokenValidationOptions.JWTAccessTokenConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := o.TokenValidationOptions.RegisterTokenNameSchemes(); err != nil {
+		return nil, err
+	}
 
 	return serverConfig, nil
 }
@@ -205,6 +255,7 @@ var specialDefaultResourcePrefixes = map[schema.GroupResource]string{
 
 	{Resource: "oauthclients", Group: "oauth.openshift.io"}:              "oauth/clients",
 	{Resource: "oauthclientauthorizations", Group: "oauth.openshift.io"}: "oauth/clientauthorizations",
+	{Resource: "sessionpolicies", Group: "oauth.openshift.io"}:           "oauth/sessionpolicies",
 
 	{Resource: "identities", Group: "user.openshift.io"}: "useridentities",
 }
@@ -26,6 +26,10 @@ import (
 	userclient "github.com/openshift/client-go/user/clientset/versioned"
 	oauthapiserver "github.com/openshift/oauth-apiserver/pkg/cmd/oauth-apiserver"
 	oauthapiservertesting "github.com/openshift/oauth-apiserver/pkg/cmd/oauth-apiserver/testing"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/introspection"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/revocation"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/userinfo"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/jwtaccesstoken"
 	tokenvalidationoptions "github.com/openshift/oauth-apiserver/pkg/tokenvalidation/options"
 )
 
@@ -142,7 +146,7 @@ func TestAddFlags(t *testing.T) {
 			Authorization: &genericapiserveroptions.DelegatingAuthorizationOptions{
 				AllowCacheTTL:       time.Second * 10,
 				DenyCacheTTL:        time.Second * 10,
-				AlwaysAllowPaths:    []string{"/healthz", "/readyz", "/livez"},
+				AlwaysAllowPaths:    []string{"/healthz", "/readyz", "/livez", jwtaccesstoken.DiscoveryPath, jwtaccesstoken.JWKSPath, userinfo.Path, introspection.Path, revocation.Path},
 				AlwaysAllowGroups:   []string{"system:masters"},
 				ClientTimeout:       time.Second * 10,
 				WebhookRetryBackoff: genericapiserveroptions.DefaultAuthWebhookRetryBackoff(),
@@ -200,7 +204,16 @@ func TestAddFlags(t *testing.T) {
 			EgressSelector: &genericapiserveroptions.EgressSelectorOptions{},
 			Traces:         &genericapiserveroptions.TracingOptions{},
 		},
-		TokenValidationOptions: &tokenvalidationoptions.TokenValidationOptions{},
+		TokenValidationOptions: &tokenvalidationoptions.TokenValidationOptions{
+			JWTAccessTokenMaxLifetime:      15 * time.Minute,
+			TokenValidators:                []string{"Expiration", "MaxAge", "SessionLifetime", "UserUID", "InactivityTimeout"},
+			TokenReviewUserFailureBurst:    100,
+			TokenReviewClientFailureBurst:  10,
+			TokenReviewLockoutDuration:     time.Minute,
+			PersonalAccessTokenMaxLifetime: 90 * 24 * time.Hour,
+			AccessTokenQuotaPolicy:         "Reject",
+			AccessTokenLifetimePolicy:      "Clamp",
+		},
 	}
 
 	// setting the FeatureGate to nil since there is no value in comparing a FG instance
@@ -257,6 +270,8 @@ func TestOAuthAPIServerConfig(t *testing.T) {
 		"--secure-port=0",
 		"--kubeconfig", fakeKubeConfigPath,
 		"--enable-priority-and-fairness=false",
+		"--disable-bootstrap-authenticator",
+		"--bootstrap-user-rotation-grace-period=1m",
 	}
 
 	// act
@@ -284,4 +299,10 @@ func TestOAuthAPIServerConfig(t *testing.T) {
 	if target.GenericConfig.FlowControl != nil {
 		t.Fatal("PriorityAndFairness wasn't disabled")
 	}
+	if !target.ExtraConfig.DisableBootstrapAuthenticator {
+		t.Error("expected the bootstrap authenticator to be disabled via --disable-bootstrap-authenticator")
+	}
+	if target.ExtraConfig.BootstrapUserRotationGracePeriod != time.Minute {
+		t.Errorf("incorrect value of target.ExtraConfig.BootstrapUserRotationGracePeriod = %v, expected 1m", target.ExtraConfig.BootstrapUserRotationGracePeriod)
+	}
 }
@@ -0,0 +1,29 @@
+package oauth
+
+const (
+	// SessionStartAnnotation holds the RFC3339 time at which the user originally authorized
+	// the session a token belongs to. It is set on OAuthAuthorizeTokens and carried over to
+	// the OAuthAccessTokens created from them, so that the absolute session lifetime is measured
+	// from the original login no matter how many times the client gets a new token.
+	// It is reserved for the server: if the absolute session lifetime is limited, new
+	// OAuthAuthorizeTokens continue the session the user has with the client unless the
+	// user logged in interactively, see InteractiveLoginAnnotation, and start a new
+	// session otherwise.
+	SessionStartAnnotation = "oauth.openshift.io/session-start"
+
+	// InteractiveLoginAnnotation is set to "true" by the OAuth server on an OAuthAuthorizeToken
+	// it creates after the user logged in interactively. The token then starts a new session
+	// rather than continuing the session the user has with the client. It is only read from
+	// create requests and never stored.
+	InteractiveLoginAnnotation = "oauth.openshift.io/interactive-login"
+
+	// LastUsedAnnotation holds the RFC3339 time, truncated to the minute, at which an
+	// OAuthAccessToken was last seen by the token authenticator. It is only updated when
+	// it is several minutes old, so it trails the actual last use by a few minutes at most.
+	// It is reserved for the server and can only move forward.
+	LastUsedAnnotation = "oauth.openshift.io/last-used"
+
+	// DescriptionAnnotation holds a description of an OAuthAccessToken. Besides the labels,
+	// it is the only metadata owners can change on their tokens through useroauthaccesstokens.
+	DescriptionAnnotation = "oauth.openshift.io/description"
+)
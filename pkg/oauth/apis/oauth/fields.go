@@ -7,6 +7,11 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// UserOAuthAccessTokenCurrentTokenField selects the UserOAuthAccessToken the request is
+// authenticated with when "true". The useroauthaccesstokens storage resolves it to the
+// name of that token.
+const UserOAuthAccessTokenCurrentTokenField = "currentToken"
+
 func OAuthAccessTokenFieldSelector(obj runtime.Object, fieldSet fields.Set) error {
 	oauthAccessToken, ok := obj.(*OAuthAccessToken)
 	if !ok {
@@ -7,6 +7,10 @@ import (
 
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	oauthapiv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth/v1"
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+	tokenreviewbatchv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewbatch/v1"
+	tokenreviewdiagnosticv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewdiagnostic/v1"
+	useroauthaccesstokenrequestv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/useroauthaccesstokenrequest/v1"
 )
 
 func init() {
@@ -16,5 +20,9 @@ func init() {
 // Install registers the API group and adds types to a scheme
 func Install(scheme *runtime.Scheme) {
 	utilruntime.Must(oauthapiv1.Install(scheme))
+	utilruntime.Must(sessionpolicyv1.Install(scheme))
+	utilruntime.Must(tokenreviewbatchv1.Install(scheme))
+	utilruntime.Must(tokenreviewdiagnosticv1.Install(scheme))
+	utilruntime.Must(useroauthaccesstokenrequestv1.Install(scheme))
 	utilruntime.Must(scheme.SetVersionPriority(oauthv1.GroupVersion))
 }
@@ -225,6 +225,11 @@ type RedirectReference struct {
 	Name  string
 }
 
+// PersonalAccessTokenClientName is the client name of the tokens users create for themselves
+// through useroauthaccesstokenrequests. The name is reserved: there is no OAuthClient of this name,
+// the tokens are not issued through a client, and no other access token may use it.
+const PersonalAccessTokenClientName = "openshift-personal-access-token"
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type UserOAuthAccessToken OAuthAccessToken
@@ -4,6 +4,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	v1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
 )
 
 func addFieldSelectorKeyConversions(scheme *runtime.Scheme) error {
@@ -61,7 +63,11 @@ func oauthClientAuthorizationFieldSelectorKeyConversionFunc(label, value string)
 
 func userOAuthClientAuthorizationFieldSelectorKeyConversionFunc(label, value string) (internalLabel, internalValue string, err error) {
 	switch label {
-	case "clientName":
+	case "clientName",
+		"userName",
+		"userUID",
+		"authorizeToken",
+		oauthapi.UserOAuthAccessTokenCurrentTokenField:
 		return label, value, nil
 	default:
 		return runtime.DefaultMetaV1FieldSelectorConversion(label, value)
@@ -6,6 +6,7 @@ import (
 	v1 "github.com/openshift/api/oauth/v1"
 	"github.com/openshift/oauth-apiserver/pkg/apitesting"
 	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"k8s.io/apimachinery/pkg/fields"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -31,4 +32,14 @@ func TestFieldSelectorConversions(t *testing.T) {
 		AllowedExternalFieldKeys: []string{"clientName", "userName", "userUID"},
 		FieldKeyEvaluatorFn:      oauthapi.OAuthClientAuthorizationFieldSelector,
 	}.Check(t)
+	apitesting.FieldKeyCheck{
+		SchemeBuilder: []func(*runtime.Scheme) error{Install},
+		Kind:          v1.GroupVersion.WithKind("UserOAuthAccessToken"),
+		// Ensure previously supported labels have conversions. DO NOT REMOVE THINGS FROM THIS LIST
+		AllowedExternalFieldKeys: []string{"clientName", "userName", "userUID", "authorizeToken"},
+		FieldKeyEvaluatorFn: func(obj runtime.Object, fieldSet fields.Set) error {
+			// useroauthaccesstokens are stored as oauthaccesstokens
+			return oauthapi.OAuthAccessTokenFieldSelector((*oauthapi.OAuthAccessToken)(obj.(*oauthapi.UserOAuthAccessToken)), fieldSet)
+		},
+	}.Check(t)
 }
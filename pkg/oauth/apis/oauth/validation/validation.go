@@ -5,10 +5,13 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"k8s.io/apimachinery/pkg/api/validation"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/api/validation/path"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/authentication/serviceaccount"
 
@@ -17,6 +20,7 @@ import (
 	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
 	scopemetadata "github.com/openshift/library-go/pkg/authorization/scopemetadata"
 	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
 )
 
 const (
@@ -26,6 +30,11 @@ const (
 	// It also defines the ticker interval for the token update routine as
 	// MinimumInactivityTimeoutSeconds / 3 is used there.
 	MinimumInactivityTimeoutSeconds = 5 * 60
+	// MaxDescriptionLength is the maximum number of characters in the description of a token.
+	MaxDescriptionLength = 256
+	// MaxSessionStartClockSkew is how far the clocks of the servers that create the tokens
+	// of a session may be apart.
+	MaxSessionStartClockSkew = 5 * time.Minute
 )
 
 // PKCE [RFC7636] code challenge methods supported
@@ -33,7 +42,6 @@ const (
 const (
 	codeChallengeMethodPlain  = "plain"
 	codeChallengeMethodSHA256 = "S256"
-	sha256Prefix              = "sha256~"
 )
 
 var CodeChallengeMethodsSupported = []string{codeChallengeMethodPlain, codeChallengeMethodSHA256}
@@ -47,8 +55,8 @@ func ValidateTokenName(name string, prefix bool) []string {
 		return []string{fmt.Sprintf("must be at least %d characters long", MinTokenLength)}
 	}
 
-	if !strings.HasPrefix(name, sha256Prefix) {
-		return []string{fmt.Sprintf("only the new format of tokens (prefixed with sha256~) is allowed")}
+	if !tokenname.HasSupportedPrefix(name) {
+		return []string{fmt.Sprintf("only the new format of tokens (prefixed with one of %s) is allowed", strings.Join(tokenname.Prefixes(), ", "))}
 	}
 	return nil
 }
@@ -96,6 +104,9 @@ func ValidateAccessToken(accessToken *oauthapi.OAuthAccessToken) field.ErrorList
 	if accessToken.ExpiresIn < 0 {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("expiresIn"), accessToken.ExpiresIn, "cannot be a negative value"))
 	}
+	allErrs = append(allErrs, validateSessionStart(accessToken.Annotations, accessToken.CreationTimestamp, field.NewPath("metadata", "annotations"))...)
+	allErrs = append(allErrs, validateLastUsed(accessToken.Annotations, field.NewPath("metadata", "annotations"))...)
+	allErrs = append(allErrs, validateDescription(accessToken.Annotations, field.NewPath("metadata", "annotations"))...)
 
 	return allErrs
 }
@@ -121,6 +132,9 @@ func ValidateAccessTokenUpdate(newToken, oldToken *oauthapi.OAuthAccessToken) fi
 		allErrs = append(allErrs, field.Invalid(field.NewPath("inactivityTimeoutSeconds"), newToken.InactivityTimeoutSeconds,
 			"cannot update non-timing-out token"))
 	}
+	allErrs = append(allErrs, validateSessionStartUpdate(newToken.Annotations, oldToken.Annotations, field.NewPath("metadata", "annotations"))...)
+	allErrs = append(allErrs, validateLastUsedUpdate(newToken.Annotations, oldToken.Annotations, field.NewPath("metadata", "annotations"))...)
+	allErrs = append(allErrs, validateDescription(newToken.Annotations, field.NewPath("metadata", "annotations"))...)
 	copied := *oldToken
 	copied.ObjectMeta = newToken.ObjectMeta
 	// allow only InactivityTimeoutSeconds to be changed
@@ -164,19 +178,101 @@ func ValidateAuthorizeToken(authorizeToken *oauthapi.OAuthAuthorizeToken) field.
 	if authorizeToken.ExpiresIn <= 0 {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("expiresIn"), authorizeToken.ExpiresIn, "must be greater than zero"))
 	}
+	allErrs = append(allErrs, validateSessionStart(authorizeToken.Annotations, authorizeToken.CreationTimestamp, field.NewPath("metadata", "annotations"))...)
 
 	return allErrs
 }
 
 func ValidateAuthorizeTokenUpdate(newToken, oldToken *oauthapi.OAuthAuthorizeToken) field.ErrorList {
 	allErrs := validation.ValidateObjectMetaUpdate(&newToken.ObjectMeta, &oldToken.ObjectMeta, field.NewPath("metadata"))
+	allErrs = append(allErrs, validateSessionStartUpdate(newToken.Annotations, oldToken.Annotations, field.NewPath("metadata", "annotations"))...)
 	copied := *oldToken
 	copied.ObjectMeta = newToken.ObjectMeta
 	return append(allErrs, validation.ValidateImmutableField(newToken, &copied, field.NewPath(""))...)
 }
 
+// validateSessionStart makes sure a session does not start after the token was created,
+// a session that starts in the future would outlive its absolute lifetime. The session start
+// and the creation of a token can come from the clocks of different servers, so a session
+// may start up to MaxSessionStartClockSkew after the creation.
+func validateSessionStart(annotations map[string]string, created metav1.Time, fldPath *field.Path) field.ErrorList {
+	sessionStart, ok := annotations[oauthapi.SessionStartAnnotation]
+	if !ok {
+		return nil
+	}
+	start, err := time.Parse(time.RFC3339, sessionStart)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Key(oauthapi.SessionStartAnnotation), sessionStart, "must be a RFC3339 timestamp")}
+	}
+	if !created.IsZero() && start.After(created.Add(MaxSessionStartClockSkew)) {
+		return field.ErrorList{field.Invalid(fldPath.Key(oauthapi.SessionStartAnnotation), sessionStart, "cannot be after the creation of the token")}
+	}
+	return nil
+}
+
+// validateSessionStartUpdate makes sure the session start cannot be moved, otherwise
+// a session could be extended beyond its absolute lifetime
+func validateSessionStartUpdate(newAnnotations, oldAnnotations map[string]string, fldPath *field.Path) field.ErrorList {
+	newSessionStart, newOK := newAnnotations[oauthapi.SessionStartAnnotation]
+	oldSessionStart, oldOK := oldAnnotations[oauthapi.SessionStartAnnotation]
+	if newOK != oldOK || newSessionStart != oldSessionStart {
+		return field.ErrorList{field.Invalid(fldPath.Key(oauthapi.SessionStartAnnotation), newSessionStart, "field is immutable")}
+	}
+	return nil
+}
+
+func validateLastUsed(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	lastUsed, ok := annotations[oauthapi.LastUsedAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, lastUsed); err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Key(oauthapi.LastUsedAnnotation), lastUsed, "must be a RFC3339 timestamp")}
+	}
+	return nil
+}
+
+// validateLastUsedUpdate makes sure the last use of a token does not move backwards, it
+// may be updated concurrently by several servers and must not be removed to hide a use
+func validateLastUsedUpdate(newAnnotations, oldAnnotations map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := validateLastUsed(newAnnotations, fldPath)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	oldValue, ok := oldAnnotations[oauthapi.LastUsedAnnotation]
+	if !ok {
+		return nil
+	}
+	oldLastUsed, err := time.Parse(time.RFC3339, oldValue)
+	if err != nil {
+		return nil
+	}
+	newValue, ok := newAnnotations[oauthapi.LastUsedAnnotation]
+	if !ok {
+		return field.ErrorList{field.Required(fldPath.Key(oauthapi.LastUsedAnnotation), "cannot be removed")}
+	}
+	if newLastUsed, _ := time.Parse(time.RFC3339, newValue); newLastUsed.Before(oldLastUsed) {
+		return field.ErrorList{field.Invalid(fldPath.Key(oauthapi.LastUsedAnnotation), newValue, fmt.Sprintf("cannot be before the current value=%s", oldValue))}
+	}
+	return nil
+}
+
+func validateDescription(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	description, ok := annotations[oauthapi.DescriptionAnnotation]
+	if !ok {
+		return nil
+	}
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return field.ErrorList{field.TooLong(fldPath.Key(oauthapi.DescriptionAnnotation), "", MaxDescriptionLength)}
+	}
+	return nil
+}
+
 func ValidateClient(client *oauthapi.OAuthClient) field.ErrorList {
 	allErrs := validation.ValidateObjectMeta(&client.ObjectMeta, false, apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
+	if client.Name == oauthapi.PersonalAccessTokenClientName {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), client.Name, "is reserved for the client name of personal access tokens"))
+	}
 	for i, redirect := range client.RedirectURIs {
 		if ok, msg := ValidateRedirectURI(redirect); !ok {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("redirectURIs").Index(i), redirect, msg))
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -201,6 +202,14 @@ func TestValidateClient(t *testing.T) {
 			T: field.ErrorTypeRequired,
 			F: "metadata.name",
 		},
+		"personal access token client name": {
+			Client: oauthapi.OAuthClient{
+				ObjectMeta:  metav1.ObjectMeta{Name: oauthapi.PersonalAccessTokenClientName},
+				GrantMethod: "prompt",
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.name",
+		},
 		"no grant method": {
 			Client: oauthapi.OAuthClient{
 				ObjectMeta: metav1.ObjectMeta{Name: "name"},
@@ -300,6 +309,23 @@ func TestValidateAccessTokens(t *testing.T) {
 		t.Errorf("expected success: %v", errs)
 	}
 
+	// the clock of the server that started the session may be ahead
+	errs = ValidateAccessToken(&oauthapi.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "sha256~accessTokenNameWithMinLen",
+			CreationTimestamp: metav1.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Annotations:       map[string]string{oauthapi.SessionStartAnnotation: "2020-01-01T00:01:00Z"},
+		},
+		ClientName:  "myclient",
+		UserName:    "myusername",
+		UserUID:     "myuseruid",
+		Scopes:      []string{"user:full"},
+		RedirectURI: "https://authn.mycluster.com",
+	})
+	if len(errs) != 0 {
+		t.Errorf("expected a session start within the clock skew to succeed: %v", errs)
+	}
+
 	errorCases := map[string]struct {
 		Token oauthapi.OAuthAccessToken
 		T     field.ErrorType
@@ -400,6 +426,58 @@ func TestValidateAccessTokens(t *testing.T) {
 			T: field.ErrorTypeInvalid,
 			F: "expiresIn",
 		},
+		"invalid session start": {
+			Token: oauthapi.OAuthAccessToken{
+				ObjectMeta:  metav1.ObjectMeta{Name: "sha256~accessTokenNameWithMinLen", Annotations: map[string]string{oauthapi.SessionStartAnnotation: "yesterday"}},
+				ClientName:  "myclient",
+				UserName:    "myusername",
+				UserUID:     "myuseruid",
+				Scopes:      []string{"user:check-access"},
+				RedirectURI: "https://authn.mycluster.com",
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.annotations[oauth.openshift.io/session-start]",
+		},
+		"session start after the creation": {
+			Token: oauthapi.OAuthAccessToken{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "sha256~accessTokenNameWithMinLen",
+					CreationTimestamp: metav1.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					Annotations:       map[string]string{oauthapi.SessionStartAnnotation: "2020-01-01T00:05:01Z"},
+				},
+				ClientName:  "myclient",
+				UserName:    "myusername",
+				UserUID:     "myuseruid",
+				Scopes:      []string{"user:check-access"},
+				RedirectURI: "https://authn.mycluster.com",
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.annotations[oauth.openshift.io/session-start]",
+		},
+		"invalid last used": {
+			Token: oauthapi.OAuthAccessToken{
+				ObjectMeta:  metav1.ObjectMeta{Name: "sha256~accessTokenNameWithMinLen", Annotations: map[string]string{oauthapi.LastUsedAnnotation: "just now"}},
+				ClientName:  "myclient",
+				UserName:    "myusername",
+				UserUID:     "myuseruid",
+				Scopes:      []string{"user:check-access"},
+				RedirectURI: "https://authn.mycluster.com",
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.annotations[oauth.openshift.io/last-used]",
+		},
+		"too long description": {
+			Token: oauthapi.OAuthAccessToken{
+				ObjectMeta:  metav1.ObjectMeta{Name: "sha256~accessTokenNameWithMinLen", Annotations: map[string]string{oauthapi.DescriptionAnnotation: strings.Repeat("x", MaxDescriptionLength+1)}},
+				ClientName:  "myclient",
+				UserName:    "myusername",
+				UserUID:     "myuseruid",
+				Scopes:      []string{"user:check-access"},
+				RedirectURI: "https://authn.mycluster.com",
+			},
+			T: field.ErrorTypeTooLong,
+			F: "metadata.annotations[oauth.openshift.io/description]",
+		},
 	}
 	for k, v := range errorCases {
 		errs := ValidateAccessToken(&v.Token)
@@ -627,6 +705,32 @@ func TestValidateAccessTokensUpdate(t *testing.T) {
 	if len(errs) != 0 {
 		t.Errorf("expected success: %v", errs)
 	}
+	used := valid.DeepCopy()
+	used.Annotations = map[string]string{oauthapi.LastUsedAnnotation: "2020-01-01T00:00:00Z"}
+	errs = ValidateAccessTokenUpdate(used, valid)
+	if len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	usedLater := used.DeepCopy()
+	usedLater.Annotations[oauthapi.LastUsedAnnotation] = "2020-01-01T00:10:00Z"
+	errs = ValidateAccessTokenUpdate(usedLater, used)
+	if len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	described := used.DeepCopy()
+	described.Labels = map[string]string{"purpose": "ci"}
+	described.Annotations[oauthapi.DescriptionAnnotation] = "CI token of myproject"
+	errs = ValidateAccessTokenUpdate(described, used)
+	if len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	// only the annotations of the server are restricted
+	annotated := used.DeepCopy()
+	annotated.Annotations["example.com/owner"] = "someone"
+	errs = ValidateAccessTokenUpdate(annotated, used)
+	if len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
 
 	errorCases := map[string]struct {
 		Token  oauthapi.OAuthAccessToken
@@ -674,6 +778,30 @@ func TestValidateAccessTokensUpdate(t *testing.T) {
 			T: field.ErrorTypeInvalid,
 			F: "inactivityTimeoutSeconds",
 		},
+		"add session start": {
+			Token: *valid,
+			Change: func(obj *oauthapi.OAuthAccessToken) {
+				obj.Annotations = map[string]string{oauthapi.SessionStartAnnotation: "2020-01-01T00:00:00Z"}
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.annotations[oauth.openshift.io/session-start]",
+		},
+		"move last used backwards": {
+			Token: *used,
+			Change: func(obj *oauthapi.OAuthAccessToken) {
+				obj.Annotations[oauthapi.LastUsedAnnotation] = "2019-12-31T23:59:00Z"
+			},
+			T: field.ErrorTypeInvalid,
+			F: "metadata.annotations[oauth.openshift.io/last-used]",
+		},
+		"remove last used": {
+			Token: *used,
+			Change: func(obj *oauthapi.OAuthAccessToken) {
+				delete(obj.Annotations, oauthapi.LastUsedAnnotation)
+			},
+			T: field.ErrorTypeRequired,
+			F: "metadata.annotations[oauth.openshift.io/last-used]",
+		},
 	}
 	for k, v := range errorCases {
 		newToken := v.Token.DeepCopy()
@@ -776,3 +904,17 @@ func TestValidateUserNameField(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTokenNamePrefixes(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"sha256~accessTokenNameWithMinLen":       true,
+		"sha512~accessTokenNameWithMinLen":       true,
+		"hmac-sha256~accessTokenNameWithMinLen":  false,
+		"md5~accessTokenNameWithMinLenxxxxxxxxx": false,
+		"accessTokenNameWithMinLenxxxxxxxxxxxxx": false,
+	} {
+		if reasons := ValidateTokenName(name, false); (len(reasons) == 0) != valid {
+			t.Errorf("%s: expected valid=%v, got %v", name, valid, reasons)
+		}
+	}
+}
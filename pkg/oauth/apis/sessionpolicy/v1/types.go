@@ -0,0 +1,76 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterSessionPolicyName is the name of the only session policy that is enforced.
+const ClusterSessionPolicyName = "cluster"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SessionPolicy overrides the lifetime of OAuth access tokens by the groups of their
+// user or by the identity providers the user logged in with. It is a singleton, only
+// the policy named "cluster" is enforced.
+type SessionPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the overrides.
+	Spec SessionPolicySpec `json:"spec"`
+}
+
+// SessionPolicySpec defines the minimum inactivity timeout and the overrides.
+type SessionPolicySpec struct {
+	// MinimumInactivityTimeoutSeconds is the shortest inactivity timeout any token gets,
+	// shorter timeouts of the oauthclient or of a rule are raised to it. It can only be
+	// raised above the default of 300 seconds. 0 or unset means the default.
+	// +optional
+	MinimumInactivityTimeoutSeconds int32 `json:"minimumInactivityTimeoutSeconds,omitempty"`
+
+	// Rules are the overrides. When several rules match a user, the strictest
+	// value of each limit is used.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Rules []SessionPolicyRule `json:"rules,omitempty"`
+}
+
+// SessionPolicyRule selects users by group or identity provider and limits the lifetime
+// of their tokens. A rule matches a user that is a member of any of Groups or has an
+// identity from any of IdentityProviders.
+type SessionPolicyRule struct {
+	// Name identifies the rule in validation errors.
+	Name string `json:"name"`
+
+	// Groups are the groups whose members the rule applies to.
+	// +optional
+	// +listType=atomic
+	Groups []string `json:"groups,omitempty"`
+	// IdentityProviders are the identity providers whose users the rule applies to.
+	// +optional
+	// +listType=atomic
+	IdentityProviders []string `json:"identityProviders,omitempty"`
+
+	// InactivityTimeoutSeconds is the maximum amount of time that can pass between
+	// consecutive uses of a token. 0 or unset means the rule does not limit inactivity.
+	// +optional
+	InactivityTimeoutSeconds int32 `json:"inactivityTimeoutSeconds,omitempty"`
+	// MaxAgeSeconds is the maximum age of a token, regardless of its expiration.
+	// 0 or unset means the rule does not limit the age of tokens.
+	// +optional
+	MaxAgeSeconds int64 `json:"maxAgeSeconds,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SessionPolicyList is a collection of session policies.
+type SessionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of session policies.
+	Items []SessionPolicy `json:"items"`
+}
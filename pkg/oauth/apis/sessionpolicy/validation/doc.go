@@ -0,0 +1,3 @@
+// Package validation has functions for validating the correctness of session
+// policies and explaining what is wrong with them when they aren't valid.
+package validation
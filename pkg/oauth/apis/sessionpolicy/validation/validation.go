@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oauthvalidation "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth/validation"
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+)
+
+func ValidateSessionPolicy(policy *sessionpolicyv1.SessionPolicy) field.ErrorList {
+	allErrs := validation.ValidateObjectMeta(&policy.ObjectMeta, false, validation.NameIsDNSSubdomain, field.NewPath("metadata"))
+	if policy.Name != sessionpolicyv1.ClusterSessionPolicyName {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("metadata", "name"), policy.Name, []string{sessionpolicyv1.ClusterSessionPolicyName}))
+	}
+	return append(allErrs, validateSessionPolicySpec(&policy.Spec, field.NewPath("spec"))...)
+}
+
+func ValidateSessionPolicyUpdate(newPolicy, oldPolicy *sessionpolicyv1.SessionPolicy) field.ErrorList {
+	allErrs := validation.ValidateObjectMetaUpdate(&newPolicy.ObjectMeta, &oldPolicy.ObjectMeta, field.NewPath("metadata"))
+	return append(allErrs, validateSessionPolicySpec(&newPolicy.Spec, field.NewPath("spec"))...)
+}
+
+func validateSessionPolicySpec(spec *sessionpolicyv1.SessionPolicySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// the minimum can only be raised, the timeout validator checks tokens often enough for the default only
+	minimumTimeoutSeconds := spec.MinimumInactivityTimeoutSeconds
+	switch {
+	case minimumTimeoutSeconds == 0:
+		minimumTimeoutSeconds = oauthvalidation.MinimumInactivityTimeoutSeconds
+	case minimumTimeoutSeconds < oauthvalidation.MinimumInactivityTimeoutSeconds:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minimumInactivityTimeoutSeconds"), spec.MinimumInactivityTimeoutSeconds,
+			fmt.Sprintf("must either be 0 or at least %d", oauthvalidation.MinimumInactivityTimeoutSeconds)))
+	}
+
+	names := sets.New[string]()
+	for i, rule := range spec.Rules {
+		rulePath := fldPath.Child("rules").Index(i)
+		switch {
+		case len(rule.Name) == 0:
+			allErrs = append(allErrs, field.Required(rulePath.Child("name"), ""))
+		case names.Has(rule.Name):
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		names.Insert(rule.Name)
+
+		if len(rule.Groups) == 0 && len(rule.IdentityProviders) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath, "must select at least one group or identity provider"))
+		}
+		if rule.InactivityTimeoutSeconds < 0 || (rule.InactivityTimeoutSeconds > 0 && rule.InactivityTimeoutSeconds < minimumTimeoutSeconds) {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("inactivityTimeoutSeconds"), rule.InactivityTimeoutSeconds,
+				fmt.Sprintf("must either be 0 or at least %d", minimumTimeoutSeconds)))
+		}
+		if rule.MaxAgeSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("maxAgeSeconds"), rule.MaxAgeSeconds, "must not be negative"))
+		}
+	}
+
+	return allErrs
+}
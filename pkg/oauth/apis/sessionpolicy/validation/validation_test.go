@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+)
+
+func TestValidateSessionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *sessionpolicyv1.SessionPolicy
+		wantErr int
+	}{
+		{
+			name:   "default",
+			policy: &sessionpolicyv1.SessionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		},
+		{
+			name: "valid rules",
+			policy: &sessionpolicyv1.SessionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: sessionpolicyv1.SessionPolicySpec{
+					Rules: []sessionpolicyv1.SessionPolicyRule{
+						{Name: "a", Groups: []string{"g"}, InactivityTimeoutSeconds: 300},
+						{Name: "b", IdentityProviders: []string{"p"}, MaxAgeSeconds: 60},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid rules",
+			policy: &sessionpolicyv1.SessionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: sessionpolicyv1.SessionPolicySpec{
+					Rules: []sessionpolicyv1.SessionPolicyRule{
+						{Name: "", Groups: []string{"g"}},
+						{Name: "a"},
+						{Name: "a", Groups: []string{"g"}, InactivityTimeoutSeconds: 299, MaxAgeSeconds: -1},
+					},
+				},
+			},
+			wantErr: 5,
+		},
+		{
+			name: "rules below a raised minimum",
+			policy: &sessionpolicyv1.SessionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: sessionpolicyv1.SessionPolicySpec{
+					MinimumInactivityTimeoutSeconds: 600,
+					Rules: []sessionpolicyv1.SessionPolicyRule{
+						{Name: "a", Groups: []string{"g"}, InactivityTimeoutSeconds: 300},
+					},
+				},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "lowered minimum",
+			policy: &sessionpolicyv1.SessionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       sessionpolicyv1.SessionPolicySpec{MinimumInactivityTimeoutSeconds: 60},
+			},
+			wantErr: 1,
+		},
+		{
+			name:    "not the cluster policy",
+			policy:  &sessionpolicyv1.SessionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "admins"}},
+			wantErr: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateSessionPolicy(tt.policy); len(errs) != tt.wantErr {
+				t.Errorf("ValidateSessionPolicy() = %v, want %d errors", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+)
+
+var (
+	schemeBuilder = runtime.NewSchemeBuilder(
+		addKnownTypes,
+	)
+	Install = schemeBuilder.AddToScheme
+
+	// internalGroupVersion is the internal version of the oauth.openshift.io group.
+	// TokenReviewBatch is never stored and has a single version, so the same type
+	// serves as its internal version and no conversion is needed.
+	internalGroupVersion = schema.GroupVersion{Group: oauthv1.GroupName, Version: runtime.APIVersionInternal}
+)
+
+// Resource returns the group resource of a resource in the oauth.openshift.io group.
+func Resource(resource string) schema.GroupResource {
+	return oauthv1.GroupVersion.WithResource(resource).GroupResource()
+}
+
+// Adds the list of known types to api.Scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(oauthv1.GroupVersion,
+		&TokenReviewBatch{},
+	)
+	scheme.AddKnownTypes(internalGroupVersion,
+		&TokenReviewBatch{},
+	)
+	return nil
+}
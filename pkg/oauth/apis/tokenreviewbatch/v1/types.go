@@ -0,0 +1,38 @@
+package v1
+
+import (
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TokenReviewBatch attempts to authenticate several tokens at once. Each token is
+// reviewed exactly like by a TokenReview.
+type TokenReviewBatch struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the tokens to review.
+	Spec TokenReviewBatchSpec `json:"spec"`
+
+	// Status is filled in by the server and holds the result of every review.
+	// +optional
+	Status TokenReviewBatchStatus `json:"status,omitempty"`
+}
+
+// TokenReviewBatchSpec is a list of token reviews.
+type TokenReviewBatchSpec struct {
+	// Reviews are the tokens to authenticate, each with its optional audiences.
+	// +listType=atomic
+	Reviews []authenticationv1.TokenReviewSpec `json:"reviews"`
+}
+
+// TokenReviewBatchStatus holds the results of the token reviews.
+type TokenReviewBatchStatus struct {
+	// Reviews are the results of spec.reviews, in the same order.
+	// +optional
+	// +listType=atomic
+	Reviews []authenticationv1.TokenReviewStatus `json:"reviews,omitempty"`
+}
@@ -0,0 +1,85 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	authenticationv1 "k8s.io/api/authentication/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewBatch) DeepCopyInto(out *TokenReviewBatch) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewBatch.
+func (in *TokenReviewBatch) DeepCopy() *TokenReviewBatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewBatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TokenReviewBatch) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewBatchSpec) DeepCopyInto(out *TokenReviewBatchSpec) {
+	*out = *in
+	if in.Reviews != nil {
+		in, out := &in.Reviews, &out.Reviews
+		*out = make([]authenticationv1.TokenReviewSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewBatchSpec.
+func (in *TokenReviewBatchSpec) DeepCopy() *TokenReviewBatchSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewBatchSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewBatchStatus) DeepCopyInto(out *TokenReviewBatchStatus) {
+	*out = *in
+	if in.Reviews != nil {
+		in, out := &in.Reviews, &out.Reviews
+		*out = make([]authenticationv1.TokenReviewStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewBatchStatus.
+func (in *TokenReviewBatchStatus) DeepCopy() *TokenReviewBatchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewBatchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TokenReviewDiagnostic explains why an OAuth access token does or does not authenticate.
+// Unlike a TokenReview, it reports which step of the authentication rejected the token
+// and the unmasked reason, so it is meant for cluster administrators only.
+type TokenReviewDiagnostic struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the token to diagnose.
+	Spec TokenReviewDiagnosticSpec `json:"spec"`
+
+	// Status is filled in by the server and holds the diagnosis.
+	// +optional
+	Status TokenReviewDiagnosticStatus `json:"status,omitempty"`
+}
+
+// TokenReviewDiagnosticSpec identifies the token to diagnose. Exactly one of
+// token and tokenName must be set.
+type TokenReviewDiagnosticSpec struct {
+	// Token is the opaque access token as sent by the client.
+	// +optional
+	Token string `json:"token,omitempty"`
+
+	// TokenName is the name of the OAuthAccessToken object of the token, e.g. its sha256~ hash.
+	// It allows diagnosing a token without handing over the token itself.
+	// +optional
+	TokenName string `json:"tokenName,omitempty"`
+
+	// Audiences are the audiences the token is reviewed for, like in a TokenReview.
+	// +optional
+	// +listType=atomic
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// TokenReviewDiagnosticStatus is the diagnosis of the token.
+type TokenReviewDiagnosticStatus struct {
+	// Authenticated is true if the token passes every step of the authentication.
+	// +optional
+	Authenticated bool `json:"authenticated,omitempty"`
+
+	// FailedStep is the step that rejected the token: TokenFormat, TokenLookup,
+	// UserLookup, one of the token validators or Audiences.
+	// +optional
+	FailedStep string `json:"failedStep,omitempty"`
+
+	// Reason explains why FailedStep rejected the token.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// TokenName is the name of the OAuthAccessToken object of the token.
+	// +optional
+	TokenName string `json:"tokenName,omitempty"`
+
+	// UserName is the name of the user the token was issued to.
+	// +optional
+	UserName string `json:"userName,omitempty"`
+}
@@ -0,0 +1,75 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewDiagnostic) DeepCopyInto(out *TokenReviewDiagnostic) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewDiagnostic.
+func (in *TokenReviewDiagnostic) DeepCopy() *TokenReviewDiagnostic {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewDiagnostic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TokenReviewDiagnostic) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewDiagnosticSpec) DeepCopyInto(out *TokenReviewDiagnosticSpec) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewDiagnosticSpec.
+func (in *TokenReviewDiagnosticSpec) DeepCopy() *TokenReviewDiagnosticSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewDiagnosticSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenReviewDiagnosticStatus) DeepCopyInto(out *TokenReviewDiagnosticStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenReviewDiagnosticStatus.
+func (in *TokenReviewDiagnosticStatus) DeepCopy() *TokenReviewDiagnosticStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenReviewDiagnosticStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,7 @@
+// +k8s:deepcopy-gen=package,register
+// +k8s:openapi-gen=true
+
+// +groupName=oauth.openshift.io
+// Package v1 is the UserOAuthAccessTokenRequest API of the oauth.openshift.io group. It is
+// defined here rather than in github.com/openshift/api because it is only served by this server.
+package v1
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// UserOAuthAccessTokenRequest creates a personal access token for the user of the request.
+// Like a TokenRequest, the token is only returned in the status of the response and only
+// its hash is stored, it cannot be retrieved again. The token is kept off the
+// useroauthaccesstokens resource so that audit policies can log those at any level: like
+// tokenreviews and secrets, useroauthaccesstokenrequests must be logged at the Metadata
+// level at most, e.g. with the rule
+//
+//	{level: Metadata, resources: [{group: oauth.openshift.io, resources: ["useroauthaccesstokenrequests"]}]}
+//
+// ahead of any rule that logs them at the Request or RequestResponse level.
+type UserOAuthAccessTokenRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the scopes and the lifetime of the requested token.
+	Spec UserOAuthAccessTokenRequestSpec `json:"spec"`
+
+	// Status is filled in by the server and holds the created token.
+	// +optional
+	Status UserOAuthAccessTokenRequestStatus `json:"status,omitempty"`
+}
+
+// UserOAuthAccessTokenRequestSpec describes the requested token.
+type UserOAuthAccessTokenRequestSpec struct {
+	// Scopes are the scopes of the token. They must be a subset of the scopes the request
+	// is made with and default to them.
+	// +optional
+	// +listType=atomic
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ExpiresIn is the requested lifetime of the token in seconds. It is capped by the maximum
+	// lifetime of personal access tokens and the session policy, 0 requests the longest lifetime.
+	// +optional
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
+
+// UserOAuthAccessTokenRequestStatus holds the created token.
+type UserOAuthAccessTokenRequestStatus struct {
+	// Token is the created access token. It is only returned here.
+	Token string `json:"token"`
+
+	// TokenName is the name of the UserOAuthAccessToken of the token, e.g. its sha256~ hash.
+	TokenName string `json:"tokenName"`
+
+	// ExpiresIn is the lifetime of the token in seconds, 0 if it does not expire.
+	// +optional
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
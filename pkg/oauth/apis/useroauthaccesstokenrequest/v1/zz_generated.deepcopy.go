@@ -0,0 +1,75 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserOAuthAccessTokenRequest) DeepCopyInto(out *UserOAuthAccessTokenRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserOAuthAccessTokenRequest.
+func (in *UserOAuthAccessTokenRequest) DeepCopy() *UserOAuthAccessTokenRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(UserOAuthAccessTokenRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UserOAuthAccessTokenRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserOAuthAccessTokenRequestSpec) DeepCopyInto(out *UserOAuthAccessTokenRequestSpec) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserOAuthAccessTokenRequestSpec.
+func (in *UserOAuthAccessTokenRequestSpec) DeepCopy() *UserOAuthAccessTokenRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserOAuthAccessTokenRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserOAuthAccessTokenRequestStatus) DeepCopyInto(out *UserOAuthAccessTokenRequestStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserOAuthAccessTokenRequestStatus.
+func (in *UserOAuthAccessTokenRequestStatus) DeepCopy() *UserOAuthAccessTokenRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserOAuthAccessTokenRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
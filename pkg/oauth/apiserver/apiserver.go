@@ -4,16 +4,21 @@ import (
 	"fmt"
 	"time"
 
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/group"
 	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
 	tokenunion "k8s.io/apiserver/pkg/authentication/token/union"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	oauthapiv1 "github.com/openshift/api/oauth/v1"
 	oauthclients "github.com/openshift/client-go/oauth/clientset/versioned"
@@ -25,27 +30,47 @@ import (
 	"github.com/openshift/library-go/pkg/oauth/oauthserviceaccountclient"
 	"github.com/openshift/library-go/pkg/oauth/usercache"
 
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/introspection"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
 	accesstokenetcd "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken/etcd"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthauthorizetoken"
 	authorizetokenetcd "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthauthorizetoken/etcd"
 	clientetcd "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthclient/etcd"
 	clientauthetcd "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthclientauthorization/etcd"
+	sessionpolicyetcd "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/sessionpolicy/etcd"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviewbatches"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviewdiagnostics"
 	tokenreviews "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviews"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/useroauthaccesstokenrequests"
 	useroauthaccesstokensdelegate "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/useroauthaccesstokens/delegate"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/revocation"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/userinfo"
 	"github.com/openshift/oauth-apiserver/pkg/serverscheme"
 	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/jwtaccesstoken"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
 )
 
 const (
-	defaultInformerResyncPeriod     = 10 * time.Minute
-	minimumInactivityTimeoutSeconds = 300
-	authenticatedOAuthGroup         = "system:authenticated:oauth"
+	defaultInformerResyncPeriod = 10 * time.Minute
+	authenticatedOAuthGroup     = "system:authenticated:oauth"
 )
 
 type ExtraConfig struct {
 	ServiceAccountMethod         string
 	AccessTokenInactivityTimeout time.Duration
+	AbsoluteSessionLifetime      time.Duration
 	ImplicitAudiences            authenticator.Audiences
 
+	DisableBootstrapAuthenticator    bool
+	BootstrapUserRotationGracePeriod time.Duration
+	JWTAccessTokens                  *jwtaccesstoken.Config
+	TokenValidators                  []tokenvalidation.ValidatorConfig
+	TokenReviewFailureLimits         tokenreviews.FailureLimits
+	PersonalAccessTokenMaxLifetime   time.Duration
+	AccessTokenQuota                 oauthaccesstoken.Quota
+	AccessTokenLifetimeLimit         oauthaccesstoken.LifetimeLimit
+
 	UserInformers  userinformer.SharedInformerFactory
 	OAuthInformers oauthinformer.SharedInformerFactory
 }
@@ -124,7 +149,59 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		return nil
 	}
 
-	v1Storage, storagePostStartHooks, err := c.newV1RESTStorage(coreV1Client, oauthClient, userClient)
+	// the served session policy applies to authentication and personal access tokens alike
+	sessionPolicies, err := c.sessionPolicyInformer()
+	if err != nil {
+		return nil, err
+	}
+	postStartHooks["openshift.io-StartSessionPolicyInformer"] = func(ctx genericapiserver.PostStartHookContext) error {
+		go sessionPolicies.Run(ctx.Done())
+		return nil
+	}
+	sessionPolicyEvaluator := tokenvalidation.NewSessionPolicyEvaluator(sessionPolicies, usercache.NewGroupCache(c.ExtraConfig.UserInformers.User().V1().Groups()))
+
+	openshiftAuthenticators, validators, tokenDiagnoser, authenticatorPostStartHooks, err := c.getOpenShiftAuthenticators(coreV1Client, oauthClient, userClient, sessionPolicyEvaluator)
+	if err != nil {
+		return nil, err
+	}
+	tokenAuthenticator := tokenunion.New(openshiftAuthenticators...)
+
+	var recorder record.EventRecorder
+	if c.ExtraConfig.TokenReviewFailureLimits.Enabled() || c.ExtraConfig.AccessTokenQuota.Enabled() {
+		// report lockouts of token review sources and exceeded access token quotas as events
+		eventBroadcaster := record.NewBroadcaster()
+		postStartHooks["openshift.io-StartEventBroadcaster"] = func(ctx genericapiserver.PostStartHookContext) error {
+			eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: coreV1Client.Events("")})
+			go func() {
+				<-ctx.Done()
+				eventBroadcaster.Shutdown()
+			}()
+			return nil
+		}
+		recorder = eventBroadcaster.NewRecorder(kubescheme.Scheme, corev1api.EventSource{Component: "openshift-oauth-apiserver"})
+	}
+	tokenReviewFailureLimiter := tokenreviews.NewFailureLimiter(c.ExtraConfig.TokenReviewFailureLimits, recorder)
+
+	// the live tokens are counted and the sessions of users are found in the informer of the
+	// OAuthInformers, which is only requested, and so only started, if one of them is enabled
+	var accessTokenQuota *oauthaccesstoken.QuotaEnforcer
+	// new authorize tokens only continue sessions if their lifetime is limited
+	var sessionFinder oauthauthorizetoken.SessionFinder
+	if c.ExtraConfig.AccessTokenQuota.Enabled() || c.ExtraConfig.AbsoluteSessionLifetime > 0 {
+		accessTokenInformer := c.ExtraConfig.OAuthInformers.Oauth().V1().OAuthAccessTokens().Informer()
+		if err := accessTokenInformer.AddIndexers(cache.Indexers{
+			oauthaccesstoken.ByUserIndexName:       oauthaccesstoken.ByUserIndexKeys,
+			oauthaccesstoken.ByUserClientIndexName: oauthaccesstoken.ByUserClientIndexKeys,
+		}); err != nil {
+			return nil, err
+		}
+		accessTokenQuota = oauthaccesstoken.NewQuotaEnforcer(c.ExtraConfig.AccessTokenQuota, c.enforcesInactivityTimeout(), accessTokenInformer, oauthClient.OauthV1().OAuthAccessTokens(), recorder)
+		if c.ExtraConfig.AbsoluteSessionLifetime > 0 {
+			sessionFinder = oauthaccesstoken.NewSessionFinder(accessTokenInformer, c.ExtraConfig.AbsoluteSessionLifetime, c.enforcesInactivityTimeout())
+		}
+	}
+
+	v1Storage, err := c.newV1RESTStorage(coreV1Client, oauthClient, userClient, tokenAuthenticator, tokenReviewFailureLimiter, tokenDiagnoser, accessTokenQuota, sessionFinder, sessionPolicyEvaluator)
 	if err != nil {
 		return nil, err
 	}
@@ -135,12 +212,26 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		return nil, err
 	}
 
+	// the bearer token is kept for this handler by userinfo.WithBearerToken in the handler chain
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(userinfo.Path, userinfo.NewHandler(tokenAuthenticator, c.ExtraConfig.UserInformers.User().V1().Users().Lister()))
+	// the client credentials are kept for these handlers by clientauth.WithClientCredentials in the handler chain
+	oauthClientLister := c.ExtraConfig.OAuthInformers.Oauth().V1().OAuthClients().Lister()
+	tokenIntrospector := tokenvalidation.NewTokenIntrospector(oauthClient.OauthV1().OAuthAccessTokens(), userClient.UserV1().Users(), validators...)
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(introspection.Path, introspection.NewHandler(tokenIntrospector, oauthClientLister))
+	accessTokenStorage := v1Storage["oauthaccesstokens"].(revocation.AccessTokenStorage)
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(revocation.Path, revocation.NewHandler(accessTokenStorage, oauthClientLister))
+
+	if jwtConfig := c.ExtraConfig.JWTAccessTokens; jwtConfig != nil {
+		s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(jwtaccesstoken.DiscoveryPath, jwtaccesstoken.NewDiscoveryHandler(jwtConfig.Issuer, jwtConfig.JWKSURI))
+		s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(jwtaccesstoken.JWKSPath, jwtaccesstoken.NewJWKSHandler(jwtConfig.PublicKeys))
+	}
+
 	for hookname := range postStartHooks {
 		s.GenericAPIServer.AddPostStartHookOrDie(hookname, postStartHooks[hookname])
 	}
 
-	for hookname := range storagePostStartHooks {
-		s.GenericAPIServer.AddPostStartHookOrDie(hookname, storagePostStartHooks[hookname])
+	for hookname := range authenticatorPostStartHooks {
+		s.GenericAPIServer.AddPostStartHookOrDie(hookname, authenticatorPostStartHooks[hookname])
 	}
 
 	return s, nil
@@ -150,10 +241,16 @@ func (c *completedConfig) newV1RESTStorage(
 	corev1Client corev1.CoreV1Interface,
 	oauthClient *oauthclients.Clientset,
 	userClient *userclient.Clientset,
-) (map[string]rest.Storage, map[string]genericapiserver.PostStartHookFunc, error) {
+	tokenAuthenticator authenticator.Token,
+	tokenReviewFailureLimiter *tokenreviews.FailureLimiter,
+	tokenDiagnoser *tokenvalidation.TokenDiagnoser,
+	accessTokenQuota *oauthaccesstoken.QuotaEnforcer,
+	sessionFinder oauthauthorizetoken.SessionFinder,
+	sessionPolicyEvaluator *tokenvalidation.SessionPolicyEvaluator,
+) (map[string]rest.Storage, error) {
 	clientStorage, err := clientetcd.NewREST(c.GenericConfig.RESTOptionsGetter)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
 
 	// If OAuth is disabled, set the strategy to Deny
@@ -165,7 +262,7 @@ func (c *completedConfig) newV1RESTStorage(
 
 	routeClient, err := routeclient.NewForConfig(c.kubeAPIServerClientConfig)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	combinedOAuthClientGetter := oauthserviceaccountclient.NewServiceAccountOAuthClientGetter(
@@ -176,83 +273,158 @@ func (c *completedConfig) newV1RESTStorage(
 		oauthClient.OauthV1().OAuthClients(),
 		saAccountGrantMethod,
 	)
-	authorizeTokenStorage, err := authorizetokenetcd.NewREST(c.GenericConfig.RESTOptionsGetter, combinedOAuthClientGetter)
+	authorizeTokenStorage, err := authorizetokenetcd.NewREST(c.GenericConfig.RESTOptionsGetter, combinedOAuthClientGetter, sessionFinder)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
-	accessTokenStorage, err := accesstokenetcd.NewREST(c.GenericConfig.RESTOptionsGetter, combinedOAuthClientGetter)
+	accessTokenStorage, err := accesstokenetcd.NewREST(c.GenericConfig.RESTOptionsGetter, combinedOAuthClientGetter, authorizeTokenStorage, accessTokenQuota, c.ExtraConfig.AccessTokenLifetimeLimit, c.enforcesInactivityTimeout())
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
 	clientAuthorizationStorage, err := clientauthetcd.NewREST(c.GenericConfig.RESTOptionsGetter, combinedOAuthClientGetter)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
-	userOAuthAccessTokensDelegate, err := useroauthaccesstokensdelegate.NewREST(accessTokenStorage)
+	sessionPolicyStorage, err := sessionpolicyetcd.NewREST(c.GenericConfig.RESTOptionsGetter)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
-	tokenReviewStorage, tokenReviewPostStartHooks, err := c.tokenReviewStorage(corev1Client, oauthClient, userClient)
+	// personal access tokens are capped by the maximum age of the session policy
+	userOAuthAccessTokensDelegate, err := useroauthaccesstokensdelegate.NewREST(accessTokenStorage, userClient.UserV1().Users(), sessionPolicyEvaluator, c.ExtraConfig.PersonalAccessTokenMaxLifetime)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error building REST storage: %v", err)
+		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
+	// the tokens are only returned by the requests, so that audit policies can tell them apart
+	userOAuthAccessTokenRequestStorage := useroauthaccesstokenrequests.NewREST(userOAuthAccessTokensDelegate)
+	tokenAuth := bearertoken.New(tokenAuthenticator)
+	tokenReviewStorage, err := tokenreviews.NewREST(tokenAuth, tokenReviewFailureLimiter)
+	if err != nil {
+		return nil, fmt.Errorf("error building REST storage: %v", err)
+	}
+	// batches share the authenticators and thus the caches and the failure limits with the single reviews
+	tokenReviewBatchStorage := tokenreviewbatches.NewREST(tokenAuth, tokenReviewFailureLimiter)
+	tokenReviewDiagnosticStorage := tokenreviewdiagnostics.NewREST(tokenDiagnoser)
 
 	v1Storage := map[string]rest.Storage{
-		"oauthauthorizetokens":      authorizeTokenStorage,
-		"oauthaccesstokens":         accessTokenStorage,
-		"oauthclients":              clientStorage,
-		"oauthclientauthorizations": clientAuthorizationStorage,
-		"useroauthaccesstokens":     userOAuthAccessTokensDelegate,
-		"tokenreviews":              tokenReviewStorage,
-	}
-	return v1Storage, tokenReviewPostStartHooks, nil
-}
-
-func (c *completedConfig) tokenReviewStorage(
-	corev1Client corev1.CoreV1Interface,
-	oauthClient *oauthclients.Clientset,
-	userClient *userclient.Clientset,
-) (rest.Storage, map[string]genericapiserver.PostStartHookFunc, error) {
-	openshiftAuthenticators, postStartHooks := c.getOpenShiftAuthenticators(corev1Client, oauthClient, userClient)
-
-	tokenAuth := bearertoken.New(tokenunion.New(openshiftAuthenticators...))
-	tokenReviewWrapper, err := tokenreviews.NewREST(tokenAuth)
-
-	return tokenReviewWrapper, postStartHooks, err
+		"oauthauthorizetokens":         authorizeTokenStorage,
+		"oauthaccesstokens":            accessTokenStorage,
+		"oauthclients":                 clientStorage,
+		"oauthclientauthorizations":    clientAuthorizationStorage,
+		"sessionpolicies":              sessionPolicyStorage,
+		"useroauthaccesstokens":        userOAuthAccessTokensDelegate,
+		"useroauthaccesstokenrequests": userOAuthAccessTokenRequestStorage,
+		"tokenreviews":                 tokenReviewStorage,
+		"tokenreviewbatches":           tokenReviewBatchStorage,
+		"tokenreviewdiagnostics":       tokenReviewDiagnosticStorage,
+	}
+	return v1Storage, nil
 }
 
 func (c *completedConfig) getOpenShiftAuthenticators(
 	corev1Client corev1.CoreV1Interface,
 	oauthClient *oauthclients.Clientset,
 	userClient *userclient.Clientset,
-) ([]authenticator.Token, map[string]genericapiserver.PostStartHookFunc) {
+	sessionPolicyEvaluator *tokenvalidation.SessionPolicyEvaluator,
+) ([]authenticator.Token, []tokenvalidation.OAuthTokenValidator, *tokenvalidation.TokenDiagnoser, map[string]genericapiserver.PostStartHookFunc, error) {
 	tokenAuthenticators := []authenticator.Token{}
-
-	bootstrapUserDataGetter := bootstrap.NewBootstrapUserDataGetter(corev1Client, corev1Client)
+	postStartHooks := map[string]genericapiserver.PostStartHookFunc{}
 
 	oauthInformer := c.ExtraConfig.OAuthInformers
 	userInformer := c.ExtraConfig.UserInformers
 
-	timeoutValidator := tokenvalidation.NewTimeoutValidator(oauthClient.OauthV1().OAuthAccessTokens(), oauthInformer.Oauth().V1().OAuthClients().Lister(), c.ExtraConfig.AccessTokenInactivityTimeout, minimumInactivityTimeoutSeconds)
-	// add our oauth token validator
-	validators := []tokenvalidation.OAuthTokenValidator{tokenvalidation.NewExpirationValidator(), tokenvalidation.NewUIDValidator(), timeoutValidator}
+	groupMapper := usercache.NewGroupCache(userInformer.User().V1().Groups())
 
-	postStartHooks := map[string]genericapiserver.PostStartHookFunc{}
-	postStartHooks["openshift.io-StartTokenTimeoutUpdater"] = func(ctx genericapiserver.PostStartHookContext) error {
-		go timeoutValidator.Run(ctx.Done())
-		return nil
+	// add our oauth token validators, the names tell which one rejected a token in a TokenReviewDiagnostic
+	validators, err := tokenvalidation.NewValidatorChain(c.tokenValidatorConfigs(), tokenvalidation.ValidatorDependencies{
+		Tokens:                       oauthClient.OauthV1().OAuthAccessTokens(),
+		OAuthClients:                 oauthInformer.Oauth().V1().OAuthClients().Lister(),
+		SessionPolicyEvaluator:       sessionPolicyEvaluator,
+		AccessTokenInactivityTimeout: c.ExtraConfig.AccessTokenInactivityTimeout,
+		AbsoluteSessionLifetime:      c.ExtraConfig.AbsoluteSessionLifetime,
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	for _, validator := range validators {
+		named := validator.(tokenvalidation.NamedOAuthTokenValidator)
+		runner, ok := named.OAuthTokenValidator.(tokenvalidation.ValidatorRunner)
+		if !ok {
+			continue
+		}
+		hookName := "openshift.io-StartTokenValidator" + named.Name
+		if named.Name == tokenvalidation.InactivityTimeoutValidatorName {
+			// keep the historical name of the hook
+			hookName = "openshift.io-StartTokenTimeoutUpdater"
+		}
+		postStartHooks[hookName] = func(ctx genericapiserver.PostStartHookContext) error {
+			go runner.Run(ctx.Done())
+			return nil
+		}
+	}
+
+	var jwtAuthenticator authenticator.Token
+	if jwtConfig := c.ExtraConfig.JWTAccessTokens; jwtConfig != nil {
+		// check JWT access tokens first, they never need a lookup in storage
+		jwtAuthenticator = jwtaccesstoken.NewAuthenticator(jwtConfig, userClient.UserV1().Users(), groupMapper, c.ExtraConfig.ImplicitAudiences, validators...)
+		tokenAuthenticators = append(tokenAuthenticators,
+			group.NewTokenGroupAdder(jwtAuthenticator, []string{authenticatedOAuthGroup}))
+		postStartHooks["openshift.io-StartJWTAccessTokenDenyListReloader"] = func(ctx genericapiserver.PostStartHookContext) error {
+			go jwtConfig.DenyList.Run(ctx)
+			return nil
+		}
 	}
 
-	groupMapper := usercache.NewGroupCache(userInformer.User().V1().Groups())
 	oauthTokenAuthenticator := tokenvalidation.NewTokenAuthenticator(oauthClient.OauthV1().OAuthAccessTokens(), userClient.UserV1().Users(), groupMapper, c.ExtraConfig.ImplicitAudiences, validators...)
 	tokenAuthenticators = append(tokenAuthenticators,
 		// if you have an OAuth bearer token, you're a human (usually)
 		group.NewTokenGroupAdder(oauthTokenAuthenticator, []string{authenticatedOAuthGroup}))
 
-	// add the bootstrap user token authenticator
-	tokenAuthenticators = append(tokenAuthenticators,
-		// bootstrap oauth user that can do anything, backed by a secret
-		tokenvalidation.NewBootstrapAuthenticator(oauthClient.OauthV1().OAuthAccessTokens(), bootstrapUserDataGetter, c.ExtraConfig.ImplicitAudiences, validators...))
+	var bootstrapUserDataGetter bootstrap.BootstrapUserDataGetter
+	if !c.ExtraConfig.DisableBootstrapAuthenticator {
+		// add the bootstrap user token authenticator
+		bootstrapUserDataGetter = bootstrap.NewBootstrapUserDataGetter(corev1Client, corev1Client)
+		tokenAuthenticators = append(tokenAuthenticators,
+			// bootstrap oauth user that can do anything, backed by a secret
+			tokenvalidation.NewBootstrapAuthenticator(oauthClient.OauthV1().OAuthAccessTokens(), bootstrapUserDataGetter, corev1Client, c.ExtraConfig.ImplicitAudiences, c.ExtraConfig.BootstrapUserRotationGracePeriod, validators...))
+	}
 
-	return tokenAuthenticators, postStartHooks
+	// the diagnoser checks tokens the same way as the authenticators above
+	tokenDiagnoser := tokenvalidation.NewTokenDiagnoser(oauthClient.OauthV1().OAuthAccessTokens(), userClient.UserV1().Users(), bootstrapUserDataGetter, jwtAuthenticator, c.ExtraConfig.ImplicitAudiences, validators...)
+
+	return tokenAuthenticators, validators, tokenDiagnoser, postStartHooks, nil
+}
+
+// tokenValidatorConfigs returns the configured chain of token validators, or the default chain
+func (c *completedConfig) tokenValidatorConfigs() []tokenvalidation.ValidatorConfig {
+	if c.ExtraConfig.TokenValidators != nil {
+		return c.ExtraConfig.TokenValidators
+	}
+	validatorConfigs := []tokenvalidation.ValidatorConfig{}
+	for _, name := range tokenvalidation.DefaultValidatorNames() {
+		validatorConfigs = append(validatorConfigs, tokenvalidation.ValidatorConfig{Name: name})
+	}
+	return validatorConfigs
+}
+
+// enforcesInactivityTimeout returns whether tokens time out from inactivity, which they only
+// do if the chain enforces the InactivityTimeout validator
+func (c *completedConfig) enforcesInactivityTimeout() bool {
+	return tokenvalidation.Enforces(c.tokenValidatorConfigs(), tokenvalidation.InactivityTimeoutValidatorName)
+}
+
+// sessionPolicyInformer follows the served cluster session policy through the loopback client
+func (c *completedConfig) sessionPolicyInformer() (*sessionpolicy.Informer, error) {
+	config := restclient.CopyConfig(c.GenericConfig.LoopbackClientConfig)
+	config.GroupVersion = &oauthapiv1.GroupVersion
+	config.APIPath = "/apis"
+	// session policies are defined locally and have no protobuf serialization
+	config.ContentType = runtime.ContentTypeJSON
+	config.AcceptContentTypes = runtime.ContentTypeJSON
+	config.NegotiatedSerializer = serverscheme.Codecs.WithoutConversion()
+	client, err := restclient.RESTClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return sessionpolicy.NewInformer(cache.NewListWatchFromClient(client, "sessionpolicies", metav1.NamespaceAll, fields.Everything()), defaultInformerResyncPeriod)
 }
@@ -0,0 +1,43 @@
+package apiserver
+
+import (
+	"testing"
+
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	restclient "k8s.io/client-go/rest"
+
+	oauthclients "github.com/openshift/client-go/oauth/clientset/versioned"
+	oauthinformer "github.com/openshift/client-go/oauth/informers/externalversions"
+	userclient "github.com/openshift/client-go/user/clientset/versioned"
+	userinformer "github.com/openshift/client-go/user/informers/externalversions"
+)
+
+func TestDisableBootstrapAuthenticator(t *testing.T) {
+	// the clients are never used, the informers are not started
+	clientConfig := &restclient.Config{Host: "https://127.0.0.1:1"}
+	coreV1Client := corev1.NewForConfigOrDie(clientConfig)
+	oauthClient := oauthclients.NewForConfigOrDie(clientConfig)
+	userClient := userclient.NewForConfigOrDie(clientConfig)
+
+	for _, test := range []struct {
+		disable  bool
+		expected int
+	}{
+		// the OAuth token and the bootstrap user authenticators
+		{disable: false, expected: 2},
+		{disable: true, expected: 1},
+	} {
+		c := &completedConfig{ExtraConfig: &ExtraConfig{
+			DisableBootstrapAuthenticator: test.disable,
+			UserInformers:                 userinformer.NewSharedInformerFactory(userClient, 0),
+			OAuthInformers:                oauthinformer.NewSharedInformerFactory(oauthClient, 0),
+		}}
+		authenticators, _, _, _, err := c.getOpenShiftAuthenticators(coreV1Client, oauthClient, userClient, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(authenticators) != test.expected {
+			t.Errorf("disable=%v: expected %d authenticators, got %d", test.disable, test.expected, len(authenticators))
+		}
+	}
+}
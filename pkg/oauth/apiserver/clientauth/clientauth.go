@@ -0,0 +1,70 @@
+// Package clientauth authenticates OAuth clients with their secrets on the endpoints
+// that OAuth clients call directly, like token introspection and revocation.
+package clientauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthlisters "github.com/openshift/client-go/oauth/listers/oauth/v1"
+)
+
+type clientCredentialsKey struct{}
+
+type clientCredentials struct {
+	id     string
+	secret string
+}
+
+// WithClientCredentials keeps the HTTP basic credentials of requests to paths in the
+// request context. It has to wrap the authentication filter, which removes the
+// Authorization header.
+func WithClientCredentials(handler http.Handler, paths ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, path := range paths {
+			if req.URL.Path != path {
+				continue
+			}
+			if id, secret, ok := req.BasicAuth(); ok {
+				req = req.WithContext(context.WithValue(req.Context(), clientCredentialsKey{}, clientCredentials{id: id, secret: secret}))
+			}
+			break
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// AuthenticateClient checks the client credentials from either HTTP basic authentication
+// or the request body against the secrets of the OAuth client. The form of the request
+// must have been parsed already.
+func AuthenticateClient(req *http.Request, clients oauthlisters.OAuthClientLister) (*oauthv1.OAuthClient, bool) {
+	credentials, ok := req.Context().Value(clientCredentialsKey{}).(clientCredentials)
+	if ok {
+		// RFC 6749 section 2.3.1 requires the basic credentials to be form encoded
+		id, idErr := url.QueryUnescape(credentials.id)
+		secret, secretErr := url.QueryUnescape(credentials.secret)
+		if idErr != nil || secretErr != nil {
+			return nil, false
+		}
+		credentials = clientCredentials{id: id, secret: secret}
+	} else {
+		credentials = clientCredentials{id: req.PostForm.Get("client_id"), secret: req.PostForm.Get("client_secret")}
+	}
+	if len(credentials.id) == 0 || len(credentials.secret) == 0 {
+		return nil, false
+	}
+
+	client, err := clients.Get(credentials.id)
+	if err != nil {
+		return nil, false
+	}
+	for _, secret := range append([]string{client.Secret}, client.AdditionalSecrets...) {
+		if len(secret) > 0 && subtle.ConstantTimeCompare([]byte(secret), []byte(credentials.secret)) == 1 {
+			return client, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,109 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthlisters "github.com/openshift/client-go/oauth/listers/oauth/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/clientauth"
+)
+
+// Path serves the OAuth 2.0 token introspection (RFC 7662) of OAuth access tokens.
+const Path = "/openshift/oauth/v1/introspect"
+
+// maxRequestBytes is plenty for a token and client credentials
+const maxRequestBytes = 64 * 1024
+
+// TokenIntrospector returns a token and its user if the token is valid.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*oauthv1.OAuthAccessToken, *userv1.User, error)
+}
+
+// response is the introspection response, see https://www.rfc-editor.org/rfc/rfc7662#section-2.2
+type response struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the introspection handler. Callers authenticate as an OAuth client
+// and only ever learn about the tokens issued to that client. The client credentials
+// are kept for the handler by clientauth.WithClientCredentials.
+func NewHandler(introspector TokenIntrospector, clients oauthlisters.OAuthClientLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		req.Body = http.MaxBytesReader(w, req.Body, maxRequestBytes)
+		if err := req.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		client, ok := clientauth.AuthenticateClient(req, clients)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid_client"})
+			return
+		}
+
+		token := req.PostForm.Get("token")
+		if len(token) == 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		accessToken, user, err := introspector.Introspect(req.Context(), token)
+		if err != nil {
+			klog.V(4).Infof("introspected token of client %q is not active: %v", client.Name, err)
+			writeJSON(w, http.StatusOK, response{Active: false})
+			return
+		}
+		if accessToken.ClientName != client.Name {
+			// do not disclose anything about tokens of other clients
+			writeJSON(w, http.StatusOK, response{Active: false})
+			return
+		}
+
+		resp := response{
+			Active:    true,
+			Scope:     strings.Join(accessToken.Scopes, " "),
+			ClientID:  accessToken.ClientName,
+			Username:  accessToken.UserName,
+			Subject:   string(user.UID),
+			TokenType: "Bearer",
+			IssuedAt:  accessToken.CreationTimestamp.Unix(),
+		}
+		if accessToken.ExpiresIn > 0 {
+			resp.ExpiresAt = resp.IssuedAt + accessToken.ExpiresIn
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		klog.Errorf("failed to write introspection response: %v", err)
+	}
+}
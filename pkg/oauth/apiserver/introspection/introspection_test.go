@@ -0,0 +1,140 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthlisters "github.com/openshift/client-go/oauth/listers/oauth/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/clientauth"
+)
+
+type fakeIntrospector map[string]*oauthv1.OAuthAccessToken
+
+func (f fakeIntrospector) Introspect(_ context.Context, token string) (*oauthv1.OAuthAccessToken, *userv1.User, error) {
+	accessToken, ok := f[token]
+	if !ok {
+		return nil, nil, errors.New("token lookup failed")
+	}
+	return accessToken, &userv1.User{ObjectMeta: metav1.ObjectMeta{Name: accessToken.UserName, UID: "bar"}}, nil
+}
+
+func TestIntrospection(t *testing.T) {
+	created := metav1.NewTime(time.Unix(1600000000, 0))
+	introspector := fakeIntrospector{
+		"sha256~mine":   {ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, ClientName: "resource-server", UserName: "foo", Scopes: []string{"user:info", "user:check-access"}, ExpiresIn: 3600},
+		"sha256~theirs": {ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, ClientName: "other", UserName: "foo"},
+	}
+
+	clients := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := clients.Add(&oauthv1.OAuthClient{ObjectMeta: metav1.ObjectMeta{Name: "resource-server"}, Secret: "secret", AdditionalSecrets: []string{"rotated"}}); err != nil {
+		t.Fatal(err)
+	}
+	introspectionHandler := NewHandler(introspector, oauthlisters.NewOAuthClientLister(clients))
+	handler := clientauth.WithClientCredentials(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// authentication removes the header before the request reaches the handler
+		req.Header.Del("Authorization")
+		introspectionHandler.ServeHTTP(w, req)
+	}), Path)
+
+	for _, test := range []struct {
+		name             string
+		basicAuth        []string
+		form             url.Values
+		expectedStatus   int
+		expectedResponse interface{}
+	}{
+		{
+			name:           "own token",
+			basicAuth:      []string{"resource-server", "secret"},
+			form:           url.Values{"token": {"sha256~mine"}},
+			expectedStatus: http.StatusOK,
+			expectedResponse: &response{
+				Active:    true,
+				Scope:     "user:info user:check-access",
+				ClientID:  "resource-server",
+				Username:  "foo",
+				Subject:   "bar",
+				TokenType: "Bearer",
+				IssuedAt:  1600000000,
+				ExpiresAt: 1600003600,
+			},
+		},
+		{
+			name:             "credentials in the body and an additional secret",
+			form:             url.Values{"token": {"sha256~mine"}, "client_id": {"resource-server"}, "client_secret": {"rotated"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: &response{Active: true, Scope: "user:info user:check-access", ClientID: "resource-server", Username: "foo", Subject: "bar", TokenType: "Bearer", IssuedAt: 1600000000, ExpiresAt: 1600003600},
+		},
+		{
+			name:             "token of another client",
+			basicAuth:        []string{"resource-server", "secret"},
+			form:             url.Values{"token": {"sha256~theirs"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: &response{},
+		},
+		{
+			name:             "invalid token",
+			basicAuth:        []string{"resource-server", "secret"},
+			form:             url.Values{"token": {"sha256~unknown"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: &response{},
+		},
+		{
+			name:             "wrong secret",
+			basicAuth:        []string{"resource-server", "wrong"},
+			form:             url.Values{"token": {"sha256~mine"}},
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: &errorResponse{Error: "invalid_client"},
+		},
+		{
+			name:             "unknown client",
+			basicAuth:        []string{"other", "secret"},
+			form:             url.Values{"token": {"sha256~theirs"}},
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: &errorResponse{Error: "invalid_client"},
+		},
+		{
+			name:             "no token",
+			basicAuth:        []string{"resource-server", "secret"},
+			form:             url.Values{},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: &errorResponse{Error: "invalid_request"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.basicAuth != nil {
+				req.SetBasicAuth(test.basicAuth[0], test.basicAuth[1])
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", test.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			got := reflect.New(reflect.TypeOf(test.expectedResponse).Elem()).Interface()
+			if err := json.Unmarshal(recorder.Body.Bytes(), got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.expectedResponse) {
+				t.Errorf("expected %#v, got %#v", test.expectedResponse, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// clientMaxAge returns the lifetime in seconds the client allows for its tokens, 0 if it does not
+// limit it. Clients without a maximum age of their own use the default of the OAuth server.
+func clientMaxAge(client *oauthv1.OAuthClient) int64 {
+	if client.AccessTokenMaxAgeSeconds == nil || *client.AccessTokenMaxAgeSeconds <= 0 {
+		return 0
+	}
+	return int64(*client.AccessTokenMaxAgeSeconds)
+}
+
+// clientInactivityTimeout returns the inactivity timeout in seconds the client allows for its tokens,
+// 0 if it does not limit it. Clients without a timeout of their own use the default of the OAuth server.
+func clientInactivityTimeout(client *oauthv1.OAuthClient) int32 {
+	if client.AccessTokenInactivityTimeoutSeconds == nil || *client.AccessTokenInactivityTimeoutSeconds <= 0 {
+		return 0
+	}
+	return *client.AccessTokenInactivityTimeoutSeconds
+}
+
+// exceedsLimit returns whether a lifetime or timeout exceeds the limit, 0 values are unlimited
+func exceedsLimit(value, limit int64) bool {
+	return limit > 0 && (value <= 0 || value > limit)
+}
+
+// clampToClient shortens the lifetime and the inactivity timeout of the new token to the limits of its
+// client with the clamp client limit policy. Tokens of clients that cannot be found are left to Validate.
+func (s strategy) clampToClient(ctx context.Context, token *oauthapi.OAuthAccessToken) {
+	if s.lifetime.ClientLimitPolicy != LifetimePolicyClamp || token.ClientName == oauthapi.PersonalAccessTokenClientName {
+		return
+	}
+	client, err := s.getClient(ctx, token.ClientName)
+	if err != nil {
+		return
+	}
+
+	if maxAge := clientMaxAge(client); exceedsLimit(token.ExpiresIn, maxAge) {
+		addLifetimeWarning(ctx, "the token was created to %s, it expires in %d seconds instead, the maximum age of tokens of client %q", describeExpiresIn(token.ExpiresIn), maxAge, client.Name)
+		token.ExpiresIn = maxAge
+	}
+	if timeout := clientInactivityTimeout(client); exceedsLimit(int64(token.InactivityTimeoutSeconds), int64(timeout)) {
+		addLifetimeWarning(ctx, "the token was created with an inactivity timeout of %d seconds, it times out after %d seconds instead, the inactivity timeout of tokens of client %q", token.InactivityTimeoutSeconds, timeout, client.Name)
+		token.InactivityTimeoutSeconds = timeout
+	}
+}
+
+// clientOfCreate holds the client of a new token once it was fetched
+type clientOfCreate struct {
+	name   string
+	client *oauthv1.OAuthClient
+	err    error
+}
+
+type clientOfCreateKey struct{}
+
+// WithClientOfCreate lets PrepareForCreate and Validate share the client of the new token,
+// so that it is fetched once per create.
+func WithClientOfCreate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clientOfCreateKey{}, &clientOfCreate{})
+}
+
+// getClient fetches the client, or returns the one fetched for the create before
+func (s strategy) getClient(ctx context.Context, name string) (*oauthv1.OAuthClient, error) {
+	c, ok := ctx.Value(clientOfCreateKey{}).(*clientOfCreate)
+	if !ok {
+		return s.clientGetter.Get(ctx, name, metav1.GetOptions{})
+	}
+	if c.name != name || (c.client == nil && c.err == nil) {
+		c.name = name
+		c.client, c.err = s.clientGetter.Get(ctx, name, metav1.GetOptions{})
+	}
+	return c.client, c.err
+}
+
+// validateClientLimits rejects new tokens that live longer or time out later than their client allows
+func validateClientLimits(token *oauthapi.OAuthAccessToken, client *oauthv1.OAuthClient) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if maxAge := clientMaxAge(client); exceedsLimit(token.ExpiresIn, maxAge) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("expiresIn"), token.ExpiresIn, fmt.Sprintf("must be between 1 and %d seconds, the maximum age of tokens of client %q", maxAge, client.Name)))
+	}
+	if timeout := clientInactivityTimeout(client); exceedsLimit(int64(token.InactivityTimeoutSeconds), int64(timeout)) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("inactivityTimeoutSeconds"), token.InactivityTimeoutSeconds, fmt.Sprintf("must be between 1 and %d seconds, the inactivity timeout of tokens of client %q", timeout, client.Name)))
+	}
+	return allErrs
+}
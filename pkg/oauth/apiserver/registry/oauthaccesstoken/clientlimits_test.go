@@ -0,0 +1,117 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthclient"
+)
+
+func TestClientLimits(t *testing.T) {
+	client := &oauthv1.OAuthClient{
+		ObjectMeta:                          metav1.ObjectMeta{Name: "console"},
+		AccessTokenMaxAgeSeconds:            ptr.To[int32](3600),
+		AccessTokenInactivityTimeoutSeconds: ptr.To[int32](600),
+	}
+
+	for _, test := range []struct {
+		name              string
+		policy            LifetimePolicy
+		expiresIn         int64
+		inactivityTimeout int32
+		expectExpiresIn   int64
+		expectTimeout     int32
+		expectInvalid     bool
+		expectWarnings    int
+	}{
+		{
+			name:              "limits not enforced",
+			expiresIn:         7200,
+			inactivityTimeout: 0,
+			expectExpiresIn:   7200,
+			expectTimeout:     0,
+		},
+		{
+			name:              "within the limits",
+			policy:            LifetimePolicyReject,
+			expiresIn:         1800,
+			inactivityTimeout: 300,
+			expectExpiresIn:   1800,
+			expectTimeout:     300,
+		},
+		{
+			name:              "clamp to the limits",
+			policy:            LifetimePolicyClamp,
+			expiresIn:         0,
+			inactivityTimeout: 900,
+			expectExpiresIn:   3600,
+			expectTimeout:     600,
+			expectWarnings:    2,
+		},
+		{
+			name:              "reject a longer lifetime",
+			policy:            LifetimePolicyReject,
+			expiresIn:         7200,
+			inactivityTimeout: 300,
+			expectExpiresIn:   7200,
+			expectTimeout:     300,
+			expectInvalid:     true,
+		},
+		{
+			name:              "reject a token without inactivity timeout",
+			policy:            LifetimePolicyReject,
+			expiresIn:         1800,
+			inactivityTimeout: 0,
+			expectExpiresIn:   1800,
+			expectTimeout:     0,
+			expectInvalid:     true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clients := &countingClientGetter{Getter: fakeClientGetter{client}}
+			s := strategy{clientGetter: clients, lifetime: LifetimeLimit{ClientLimitPolicy: test.policy}}
+			token := &oauthapi.OAuthAccessToken{
+				ObjectMeta:               metav1.ObjectMeta{Name: "sha256~consoleTokenWithTheMinimumLength"},
+				ClientName:               "console",
+				UserName:                 "foo",
+				UserUID:                  "bar",
+				Scopes:                   []string{"user:full"},
+				RedirectURI:              "https://console.example.com",
+				ExpiresIn:                test.expiresIn,
+				InactivityTimeoutSeconds: test.inactivityTimeout,
+			}
+
+			ctx := WithClientOfCreate(WithLifetimeWarnings(context.TODO()))
+			s.PrepareForCreate(ctx, token)
+			if token.ExpiresIn != test.expectExpiresIn || token.InactivityTimeoutSeconds != test.expectTimeout {
+				t.Errorf("expected expiresIn=%d inactivityTimeoutSeconds=%d, got %d and %d", test.expectExpiresIn, test.expectTimeout, token.ExpiresIn, token.InactivityTimeoutSeconds)
+			}
+			if errs := s.Validate(ctx, token); test.expectInvalid != (len(errs) > 0) {
+				t.Errorf("expected invalid=%v, got %v", test.expectInvalid, errs)
+			}
+			if warnings := s.WarningsOnCreate(ctx, token); len(warnings) != test.expectWarnings {
+				t.Errorf("expected %d warnings, got %v", test.expectWarnings, warnings)
+			}
+			if clients.gets != 1 {
+				t.Errorf("expected the client to be fetched once, got %d times", clients.gets)
+			}
+		})
+	}
+}
+
+// countingClientGetter counts the clients it gets
+type countingClientGetter struct {
+	oauthclient.Getter
+	gets int
+}
+
+func (g *countingClientGetter) Get(ctx context.Context, name string, options metav1.GetOptions) (*oauthv1.OAuthClient, error) {
+	g.gets++
+	return g.Getter.Get(ctx, name, options)
+}
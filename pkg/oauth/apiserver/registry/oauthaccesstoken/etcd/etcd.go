@@ -1,11 +1,16 @@
 package etcd
 
 import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/util/dryrun"
 
 	"github.com/openshift/api/oauth"
 	"github.com/openshift/oauth-apiserver/pkg/printers"
@@ -17,16 +22,25 @@ import (
 	oauthprinters "github.com/openshift/oauth-apiserver/pkg/oauth/printers/internalversion"
 )
 
+// inactivityTimeoutGracePeriod is how long tokens that timed out from inactivity are kept in
+// storage, it is well above the interval the inactivity timeouts are updated in
+const inactivityTimeoutGracePeriod = 5 * time.Minute
+
 // rest implements a RESTStorage for access tokens against etcd
 type REST struct {
 	*registry.Store
+
+	quota *oauthaccesstoken.QuotaEnforcer
 }
 
 var _ rest.StandardStorage = &REST{}
 
-// NewREST returns a RESTStorage object that will work against access tokens
-func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Getter) (*REST, error) {
-	strategy := oauthaccesstoken.NewStrategy(clientGetter)
+// NewREST returns a RESTStorage object that will work against access tokens.
+// The authorizeTokens getter is used to carry the session start over from authorize tokens,
+// new tokens are checked against the quota and the lifetime limit. Tokens are only removed
+// from storage when they time out from inactivity if the inactivity timeout is enforced.
+func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Getter, authorizeTokens rest.Getter, quota *oauthaccesstoken.QuotaEnforcer, lifetime oauthaccesstoken.LifetimeLimit, enforceInactivityTimeout bool) (*REST, error) {
+	strategy := oauthaccesstoken.NewStrategy(clientGetter, authorizeTokens, quota, lifetime)
 	store := &registry.Store{
 		NewFunc:                   func() runtime.Object { return &oauthapi.OAuthAccessToken{} },
 		NewListFunc:               func() runtime.Object { return &oauthapi.OAuthAccessTokenList{} },
@@ -35,11 +49,7 @@ func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Gett
 
 		TableConvertor: printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(oauthprinters.AddOAuthOpenShiftHandler)},
 
-		TTLFunc: func(obj runtime.Object, existing uint64, update bool) (uint64, error) {
-			token := obj.(*oauthapi.OAuthAccessToken)
-			expires := uint64(token.ExpiresIn)
-			return expires, nil
-		},
+		TTLFunc: ttlFunc(enforceInactivityTimeout),
 
 		CreateStrategy:      strategy,
 		UpdateStrategy:      strategy,
@@ -55,5 +65,59 @@ func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Gett
 		return nil, err
 	}
 
-	return &REST{store}, nil
+	return &REST{Store: store, quota: quota}, nil
+}
+
+// ttlFunc leases the keys of tokens for their ttl. The TTL is computed again on every update,
+// so bumping the inactivity timeout extends it.
+func ttlFunc(enforceInactivityTimeout bool) func(obj runtime.Object, existing uint64, update bool) (uint64, error) {
+	return func(obj runtime.Object, existing uint64, update bool) (uint64, error) {
+		return ttl(obj.(*oauthapi.OAuthAccessToken), time.Now(), enforceInactivityTimeout), nil
+	}
+}
+
+// ttl returns the seconds until the token expires or times out from inactivity, whichever
+// comes first, 0 if it does neither. Tokens only time out if the inactivity timeout is
+// enforced, timed out tokens are kept for a grace period, so that an inactivity timeout
+// bumped right before the deadline is not lost.
+func ttl(token *oauthapi.OAuthAccessToken, now time.Time, enforceInactivityTimeout bool) uint64 {
+	var deadline time.Time
+	if token.ExpiresIn > 0 {
+		deadline = token.CreationTimestamp.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	if enforceInactivityTimeout && token.InactivityTimeoutSeconds > 0 {
+		timeout := token.CreationTimestamp.Add(time.Duration(token.InactivityTimeoutSeconds)*time.Second + inactivityTimeoutGracePeriod)
+		if deadline.IsZero() || timeout.Before(deadline) {
+			deadline = timeout
+		}
+	}
+	if deadline.IsZero() {
+		return 0
+	}
+
+	// round up, 0 would keep the token forever
+	remaining := (deadline.Sub(now) + time.Second - 1) / time.Second
+	if remaining < 1 {
+		return 1
+	}
+	return uint64(remaining)
+}
+
+// Create marks dry runs in the context, the quota does not evict tokens for them, and
+// collects the clamped lifetimes to warn about. The client of the token is fetched once
+// for the whole create. The tokens the quota picked for eviction
+// are only evicted once the new token was created.
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx = oauthaccesstoken.WithClientOfCreate(oauthaccesstoken.WithLifetimeWarnings(ctx))
+	if options != nil && dryrun.IsDryRun(options.DryRun) {
+		ctx = oauthaccesstoken.WithDryRun(ctx)
+	} else {
+		ctx = oauthaccesstoken.WithEvictions(ctx)
+	}
+	created, err := r.Store.Create(ctx, obj, createValidation, options)
+	if err != nil {
+		return nil, err
+	}
+	r.quota.Evict(ctx)
+	return created, nil
 }
@@ -0,0 +1,228 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/api/oauth"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+)
+
+func TestTTL(t *testing.T) {
+	created := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	grace := uint64(inactivityTimeoutGracePeriod / time.Second)
+
+	for _, test := range []struct {
+		name                         string
+		expiresIn                    int64
+		inactivityTimeout            int32
+		inactivityTimeoutNotEnforced bool
+		age                          time.Duration
+		expected                     uint64
+	}{
+		{name: "never expires", expected: 0},
+		{name: "expires", expiresIn: 3600, age: 10 * time.Minute, expected: 3000},
+		{name: "times out before it expires", expiresIn: 3600, inactivityTimeout: 600, expected: 600 + grace},
+		{name: "times out without expiring", inactivityTimeout: 600, age: 5 * time.Minute, expected: 300 + grace},
+		{name: "expires before it times out", expiresIn: 600, inactivityTimeout: 3600, expected: 600},
+		{name: "bumped timeout", expiresIn: 86400, inactivityTimeout: 7200, age: time.Hour, expected: 3600 + grace},
+		{name: "inactivity timeout not enforced", expiresIn: 3600, inactivityTimeout: 600, inactivityTimeoutNotEnforced: true, expected: 3600},
+		{name: "no deadline without an enforced inactivity timeout", inactivityTimeout: 600, inactivityTimeoutNotEnforced: true, expected: 0},
+		{name: "past the deadline", expiresIn: 600, age: time.Hour, expected: 1},
+		{name: "rounded up", expiresIn: 600, age: 599*time.Second + time.Millisecond, expected: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token := &oauthapi.OAuthAccessToken{
+				ObjectMeta:               metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				ExpiresIn:                test.expiresIn,
+				InactivityTimeoutSeconds: test.inactivityTimeout,
+			}
+			if got := ttl(token, created.Add(test.age), !test.inactivityTimeoutNotEnforced); got != test.expected {
+				t.Errorf("expected a TTL of %d seconds, got %d", test.expected, got)
+			}
+		})
+	}
+}
+
+// createStorage creates the tokens, or fails to with err
+type createStorage struct {
+	storage.Interface
+	err error
+}
+
+func (s *createStorage) Create(_ context.Context, _ string, obj, out runtime.Object, _ uint64) error {
+	if s.err != nil {
+		return s.err
+	}
+	obj.(*oauthapi.OAuthAccessToken).DeepCopyInto(out.(*oauthapi.OAuthAccessToken))
+	return nil
+}
+
+func TestCreateEvictsAfterCreate(t *testing.T) {
+	old := &oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: "sha256~old", UID: types.UID("uid-old"), CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		UserName:   "foo",
+		ClientName: oauthapi.PersonalAccessTokenClientName,
+	}
+
+	for _, test := range []struct {
+		name          string
+		createErr     error
+		expectEvicted bool
+	}{
+		{name: "evicts once the token was created", expectEvicted: true},
+		{name: "keeps the tokens if the create fails", createErr: storage.NewInternalError(errors.New("etcd is unavailable"))},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client := oauthfake.NewSimpleClientset(old)
+			informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return client.OauthV1().OAuthAccessTokens().List(context.TODO(), options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return client.OauthV1().OAuthAccessTokens().Watch(context.TODO(), options)
+				},
+			}, &oauthv1.OAuthAccessToken{}, 0, cache.Indexers{
+				oauthaccesstoken.ByUserIndexName:       oauthaccesstoken.ByUserIndexKeys,
+				oauthaccesstoken.ByUserClientIndexName: oauthaccesstoken.ByUserClientIndexKeys,
+			})
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			go informer.Run(stopCh)
+			if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+				t.Fatal("the token cache did not sync")
+			}
+			client.ClearActions()
+
+			quota := oauthaccesstoken.NewQuotaEnforcer(oauthaccesstoken.Quota{PerUser: 1, Policy: oauthaccesstoken.QuotaPolicyEvictOldest}, true, informer, client.OauthV1().OAuthAccessTokens(), nil)
+			strategy := oauthaccesstoken.NewStrategy(nil, nil, quota, oauthaccesstoken.LifetimeLimit{})
+			r := &REST{
+				Store: &registry.Store{
+					NewFunc:                  func() runtime.Object { return &oauthapi.OAuthAccessToken{} },
+					DefaultQualifiedResource: oauth.Resource("oauthaccesstokens"),
+					KeyFunc:                  func(_ context.Context, name string) (string, error) { return "/oauthaccesstokens/" + name, nil },
+					ObjectNameFunc:           func(obj runtime.Object) (string, error) { return obj.(*oauthapi.OAuthAccessToken).Name, nil },
+					CreateStrategy:           strategy,
+					Storage:                  registry.DryRunnableStorage{Storage: &createStorage{err: test.createErr}},
+				},
+				quota: quota,
+			}
+
+			ctx := oauthaccesstoken.WithPersonalAccessToken(apirequest.WithNamespace(context.TODO(), metav1.NamespaceNone))
+			_, err := r.Create(ctx, &oauthapi.OAuthAccessToken{
+				ObjectMeta:  metav1.ObjectMeta{Name: "sha256~new-token-with-a-long-enough-name"},
+				UserName:    "foo",
+				UserUID:     "bar",
+				ClientName:  oauthapi.PersonalAccessTokenClientName,
+				Scopes:      []string{"user:full"},
+				RedirectURI: "urn:ietf:wg:oauth:2.0:oob",
+			}, nil, &metav1.CreateOptions{})
+			if test.createErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if test.createErr != nil && !kerrors.IsInternalError(err) {
+				t.Fatalf("expected an internal error, got %v", err)
+			}
+
+			evicted := false
+			for _, action := range client.Actions() {
+				evicted = evicted || action.GetVerb() == "delete"
+			}
+			if evicted != test.expectEvicted {
+				t.Errorf("expected evicted=%v, got %v", test.expectEvicted, client.Actions())
+			}
+		})
+	}
+}
+
+// updateStorage holds a token and the TTL its key was leased for by the last update
+type updateStorage struct {
+	storage.Interface
+	token *oauthapi.OAuthAccessToken
+	ttl   *uint64
+}
+
+func (s *updateStorage) Versioner() storage.Versioner {
+	return storage.APIObjectVersioner{}
+}
+
+func (s *updateStorage) GuaranteedUpdate(_ context.Context, _ string, destination runtime.Object, _ bool, _ *storage.Preconditions, tryUpdate storage.UpdateFunc, _ runtime.Object) error {
+	updated, ttl, err := tryUpdate(s.token.DeepCopy(), storage.ResponseMeta{TTL: 60})
+	if err != nil {
+		return err
+	}
+	s.token, s.ttl = updated.(*oauthapi.OAuthAccessToken), ttl
+	s.token.DeepCopyInto(destination.(*oauthapi.OAuthAccessToken))
+	return nil
+}
+
+func TestUpdateLeasesTheKeyAgain(t *testing.T) {
+	grace := uint64(inactivityTimeoutGracePeriod / time.Second)
+
+	for _, test := range []struct {
+		name                     string
+		enforceInactivityTimeout bool
+		expected                 uint64
+	}{
+		{name: "until the bumped inactivity timeout", enforceInactivityTimeout: true, expected: 600 + grace},
+		{name: "until the token expires if the inactivity timeout is not enforced", expected: 82800},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token := &oauthapi.OAuthAccessToken{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "sha256~token-with-a-long-enough-name",
+					ResourceVersion:   "1",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+				UserName:                 "foo",
+				UserUID:                  "bar",
+				ClientName:               "console",
+				Scopes:                   []string{"user:full"},
+				RedirectURI:              "https://console.example.com",
+				ExpiresIn:                86400,
+				InactivityTimeoutSeconds: 600,
+			}
+			tokens := &updateStorage{token: token}
+			strategy := oauthaccesstoken.NewStrategy(nil, nil, nil, oauthaccesstoken.LifetimeLimit{})
+			r := &REST{Store: &registry.Store{
+				NewFunc:                  func() runtime.Object { return &oauthapi.OAuthAccessToken{} },
+				DefaultQualifiedResource: oauth.Resource("oauthaccesstokens"),
+				KeyFunc:                  func(_ context.Context, name string) (string, error) { return "/oauthaccesstokens/" + name, nil },
+				ObjectNameFunc:           func(obj runtime.Object) (string, error) { return obj.(*oauthapi.OAuthAccessToken).Name, nil },
+				TTLFunc:                  ttlFunc(test.enforceInactivityTimeout),
+				UpdateStrategy:           strategy,
+				Storage:                  registry.DryRunnableStorage{Storage: tokens},
+			}}
+
+			// the token authenticator bumps the inactivity timeout of tokens in use
+			bumped := token.DeepCopy()
+			bumped.InactivityTimeoutSeconds = 3600 + 600
+			ctx := apirequest.WithNamespace(context.TODO(), metav1.NamespaceNone)
+			if _, _, err := r.Update(ctx, bumped.Name, rest.DefaultUpdatedObjectInfo(bumped), nil, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// allow for the seconds the test takes
+			if tokens.ttl == nil || *tokens.ttl > test.expected || *tokens.ttl < test.expected-5 {
+				t.Errorf("expected the key to be leased for %d seconds, got %v", test.expected, tokens.ttl)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// LifetimePolicy decides what happens to a new token that lives longer than the maximum lifetime.
+type LifetimePolicy string
+
+const (
+	// LifetimePolicyClamp shortens the lifetime of the new token to the maximum.
+	LifetimePolicyClamp LifetimePolicy = "Clamp"
+	// LifetimePolicyReject rejects the new token.
+	LifetimePolicyReject LifetimePolicy = "Reject"
+)
+
+// LifetimeLimit limits the lifetime of all new OAuthAccessTokens, whichever OAuth server or
+// client creates them.
+type LifetimeLimit struct {
+	// MaxLifetime is the longest lifetime of a token, tokens that never expire exceed it. 0 does not limit the lifetime.
+	MaxLifetime time.Duration
+	// DenyNonExpiring rejects tokens that never expire, even without a MaxLifetime.
+	DenyNonExpiring bool
+	Policy          LifetimePolicy
+	// ClientLimitPolicy decides what happens to a new token that lives longer or times out later
+	// than its OAuthClient allows. Empty does not enforce the limits of the clients.
+	ClientLimitPolicy LifetimePolicy
+}
+
+func (l LifetimeLimit) maxSeconds() int64 {
+	return int64(l.MaxLifetime / time.Second)
+}
+
+// exceeds returns whether a token that expires in the given seconds lives longer than the maximum
+func (l LifetimeLimit) exceeds(expiresIn int64) bool {
+	maxSeconds := l.maxSeconds()
+	return maxSeconds > 0 && (expiresIn <= 0 || expiresIn > maxSeconds)
+}
+
+// clamp shortens the lifetime of the token to the maximum with the clamp policy. It returns
+// whether it did.
+func (l LifetimeLimit) clamp(token *oauthapi.OAuthAccessToken) bool {
+	if l.Policy != LifetimePolicyClamp || !l.exceeds(token.ExpiresIn) {
+		return false
+	}
+	token.ExpiresIn = l.maxSeconds()
+	return true
+}
+
+// validate rejects the tokens that exceed the limit after clamp
+func (l LifetimeLimit) validate(token *oauthapi.OAuthAccessToken) field.ErrorList {
+	path := field.NewPath("expiresIn")
+	switch {
+	case l.exceeds(token.ExpiresIn) && token.ExpiresIn <= 0:
+		return field.ErrorList{field.Forbidden(path, fmt.Sprintf("tokens must expire within %s", l.MaxLifetime))}
+	case l.exceeds(token.ExpiresIn):
+		return field.ErrorList{field.Invalid(path, token.ExpiresIn, fmt.Sprintf("must be at most %d seconds", l.maxSeconds()))}
+	case l.DenyNonExpiring && token.ExpiresIn <= 0:
+		return field.ErrorList{field.Forbidden(path, "tokens must expire")}
+	}
+	return nil
+}
+
+// lifetimeWarnings collects the warnings about the lifetimes PrepareForCreate clamped
+type lifetimeWarnings struct {
+	warnings []string
+}
+
+type lifetimeWarningsKey struct{}
+
+// WithLifetimeWarnings lets PrepareForCreate pass the lifetimes it clamps on to WarningsOnCreate.
+func WithLifetimeWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lifetimeWarningsKey{}, &lifetimeWarnings{})
+}
+
+func addLifetimeWarning(ctx context.Context, format string, args ...interface{}) {
+	if w, ok := ctx.Value(lifetimeWarningsKey{}).(*lifetimeWarnings); ok {
+		w.warnings = append(w.warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+func getLifetimeWarnings(ctx context.Context) []string {
+	if w, ok := ctx.Value(lifetimeWarningsKey{}).(*lifetimeWarnings); ok {
+		return w.warnings
+	}
+	return nil
+}
+
+// describeExpiresIn describes a lifetime in seconds for warnings, 0 never expires
+func describeExpiresIn(expiresIn int64) string {
+	if expiresIn <= 0 {
+		return "never expire"
+	}
+	return fmt.Sprintf("expire in %d seconds", expiresIn)
+}
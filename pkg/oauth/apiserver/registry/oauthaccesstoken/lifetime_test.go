@@ -0,0 +1,95 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+func TestLifetimeLimit(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		limit         LifetimeLimit
+		expiresIn     int64
+		expected      int64
+		expectInvalid bool
+		expectWarning bool
+	}{
+		{
+			name:      "no limit",
+			limit:     LifetimeLimit{Policy: LifetimePolicyClamp},
+			expiresIn: 0,
+			expected:  0,
+		},
+		{
+			name:      "within the maximum",
+			limit:     LifetimeLimit{MaxLifetime: time.Hour, Policy: LifetimePolicyReject},
+			expiresIn: 600,
+			expected:  600,
+		},
+		{
+			name:          "clamp a longer lifetime",
+			limit:         LifetimeLimit{MaxLifetime: time.Hour, Policy: LifetimePolicyClamp},
+			expiresIn:     7200,
+			expected:      3600,
+			expectWarning: true,
+		},
+		{
+			name:          "clamp a token that never expires",
+			limit:         LifetimeLimit{MaxLifetime: time.Hour, DenyNonExpiring: true, Policy: LifetimePolicyClamp},
+			expiresIn:     0,
+			expected:      3600,
+			expectWarning: true,
+		},
+		{
+			name:          "reject a longer lifetime",
+			limit:         LifetimeLimit{MaxLifetime: time.Hour, Policy: LifetimePolicyReject},
+			expiresIn:     7200,
+			expected:      7200,
+			expectInvalid: true,
+		},
+		{
+			name:          "reject a token that never expires",
+			limit:         LifetimeLimit{MaxLifetime: time.Hour, Policy: LifetimePolicyReject},
+			expiresIn:     0,
+			expected:      0,
+			expectInvalid: true,
+		},
+		{
+			name:          "deny a token that never expires without a maximum",
+			limit:         LifetimeLimit{DenyNonExpiring: true, Policy: LifetimePolicyClamp},
+			expiresIn:     0,
+			expected:      0,
+			expectInvalid: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := strategy{clientGetter: fakeClientGetter{}, lifetime: test.limit}
+			token := &oauthapi.OAuthAccessToken{
+				ObjectMeta:  metav1.ObjectMeta{Name: "sha256~personalAccessTokenWithMinLen"},
+				ClientName:  oauthapi.PersonalAccessTokenClientName,
+				UserName:    "foo",
+				UserUID:     "bar",
+				Scopes:      []string{"user:full"},
+				RedirectURI: "urn:ietf:wg:oauth:2.0:oob",
+				ExpiresIn:   test.expiresIn,
+			}
+
+			ctx := WithPersonalAccessToken(WithLifetimeWarnings(context.TODO()))
+			s.PrepareForCreate(ctx, token)
+			if token.ExpiresIn != test.expected {
+				t.Errorf("expected the token to expire in %d seconds, got %d", test.expected, token.ExpiresIn)
+			}
+			if errs := s.Validate(ctx, token); test.expectInvalid != (len(errs) > 0) {
+				t.Errorf("expected invalid=%v, got %v", test.expectInvalid, errs)
+			}
+			if warnings := s.WarningsOnCreate(ctx, token); test.expectWarning != (len(warnings) > 0) {
+				t.Errorf("expected warning=%v, got %v", test.expectWarning, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// QuotaPolicy decides what happens to a new token that exceeds a quota.
+type QuotaPolicy string
+
+const (
+	// QuotaPolicyReject rejects the new token.
+	QuotaPolicyReject QuotaPolicy = "Reject"
+	// QuotaPolicyEvictOldest deletes the oldest tokens that count against the quota to make room for the new token.
+	QuotaPolicyEvictOldest QuotaPolicy = "EvictOldest"
+)
+
+const (
+	// ByUserIndexName indexes OAuthAccessTokens by the name of their user
+	ByUserIndexName = "oauthaccesstoken-by-user"
+	// ByUserClientIndexName indexes OAuthAccessTokens by the names of their user and client
+	ByUserClientIndexName = "oauthaccesstoken-by-user-client"
+
+	limitUser       = "user"
+	limitUserClient = "user_client"
+)
+
+var (
+	accessTokenQuotaExceeded = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "openshift_oauth_apiserver_access_token_quota_exceeded_total",
+			Help:           "Number of new OAuth access tokens that exceeded a quota, by limit and by the policy that was applied.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"limit", "policy"},
+	)
+	evictedAccessTokens = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "openshift_oauth_apiserver_access_tokens_evicted_total",
+			Help:           "Number of OAuth access tokens that were deleted to make room for new tokens, by limit.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"limit"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(accessTokenQuotaExceeded, evictedAccessTokens)
+}
+
+// Quota limits the live OAuthAccessTokens per user and per user and client. A limit of 0 disables it.
+type Quota struct {
+	PerUser       int
+	PerUserClient int
+	Policy        QuotaPolicy
+}
+
+// Enabled returns whether any limit is set.
+func (q Quota) Enabled() bool {
+	return q.PerUser > 0 || q.PerUserClient > 0
+}
+
+// ByUserIndexKeys is a cache.IndexFunc for ByUserIndexName
+func ByUserIndexKeys(obj interface{}) ([]string, error) {
+	token, ok := obj.(*oauthv1.OAuthAccessToken)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an OAuthAccessToken", obj)
+	}
+	return []string{token.UserName}, nil
+}
+
+// ByUserClientIndexKeys is a cache.IndexFunc for ByUserClientIndexName
+func ByUserClientIndexKeys(obj interface{}) ([]string, error) {
+	token, ok := obj.(*oauthv1.OAuthAccessToken)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an OAuthAccessToken", obj)
+	}
+	return []string{userClientKey(token.UserName, token.ClientName)}, nil
+}
+
+func userClientKey(userName, clientName string) string {
+	return userName + "/" + clientName
+}
+
+// QuotaEnforcer checks new OAuthAccessTokens against the quota. The live tokens are counted
+// in an informer cache, so tokens created at the same time on other servers may exceed the
+// quota for a moment.
+type QuotaEnforcer struct {
+	quota Quota
+	// enforceInactivityTimeout excludes the tokens that timed out from inactivity from the count
+	enforceInactivityTimeout bool
+	indexer                  cache.Indexer
+	synced                   cache.InformerSynced
+	tokens                   oauthv1client.OAuthAccessTokenInterface
+	recorder                 record.EventRecorder
+	clock                    clock.Clock
+}
+
+// NewQuotaEnforcer returns an enforcer of the quota, or nil if no limit is set. The nil enforcer
+// admits every token. The indexer must have the ByUserIndexName and ByUserClientIndexName indexes,
+// tokens are evicted through the tokens client and the recorder reports exceeded quotas as events.
+// Tokens that timed out from inactivity only stop counting if the inactivity timeout is enforced.
+func NewQuotaEnforcer(quota Quota, enforceInactivityTimeout bool, informer cache.SharedIndexInformer, tokens oauthv1client.OAuthAccessTokenInterface, recorder record.EventRecorder) *QuotaEnforcer {
+	if !quota.Enabled() {
+		return nil
+	}
+	return &QuotaEnforcer{
+		quota:                    quota,
+		enforceInactivityTimeout: enforceInactivityTimeout,
+		indexer:                  informer.GetIndexer(),
+		synced:                   informer.HasSynced,
+		tokens:                   tokens,
+		recorder:                 recorder,
+		clock:                    clock.RealClock{},
+	}
+}
+
+// Admit checks the new token against the per user and client limit first and then against the
+// per user limit. With the QuotaPolicyEvictOldest policy, the oldest tokens over a limit are
+// picked for eviction in the evictions of the context, Evict deletes them once the new token
+// was created. Dry runs and creates without evictions in their context evict no tokens.
+func (e *QuotaEnforcer) Admit(ctx context.Context, token *oauthapi.OAuthAccessToken) field.ErrorList {
+	if e == nil {
+		return nil
+	}
+	if !e.synced() {
+		// the tokens cannot be counted yet
+		klog.V(4).Infof("Access token quota of user=%q client=%q is not enforced before the token cache is synced", token.UserName, token.ClientName)
+		return nil
+	}
+
+	evicted := sets.New[string]()
+	if e.quota.PerUserClient > 0 {
+		if err := e.admit(ctx, token, limitUserClient, ByUserClientIndexName, userClientKey(token.UserName, token.ClientName), e.quota.PerUserClient, evicted); err != nil {
+			return field.ErrorList{err}
+		}
+	}
+	if e.quota.PerUser > 0 {
+		if err := e.admit(ctx, token, limitUser, ByUserIndexName, token.UserName, e.quota.PerUser, evicted); err != nil {
+			return field.ErrorList{err}
+		}
+	}
+	return nil
+}
+
+func (e *QuotaEnforcer) admit(ctx context.Context, token *oauthapi.OAuthAccessToken, limit, index, key string, max int, evicted sets.Set[string]) *field.Error {
+	objs, err := e.indexer.ByIndex(index, key)
+	if err != nil {
+		return field.InternalError(field.NewPath("userName"), err)
+	}
+
+	now := e.clock.Now()
+	live := make([]*oauthv1.OAuthAccessToken, 0, len(objs))
+	for _, obj := range objs {
+		existing := obj.(*oauthv1.OAuthAccessToken)
+		// the cache still has the tokens evicted for another limit
+		if existing.Name == token.Name || evicted.Has(existing.Name) || expired(existing, now, e.enforceInactivityTimeout) {
+			continue
+		}
+		live = append(live, existing)
+	}
+	if len(live) < max {
+		return nil
+	}
+
+	description := fmt.Sprintf("%d access tokens per user", max)
+	if limit == limitUserClient {
+		description = fmt.Sprintf("%d access tokens per user and client", max)
+	}
+	user := &corev1.ObjectReference{Kind: "User", APIVersion: "user.openshift.io/v1", Name: token.UserName}
+
+	if e.quota.Policy != QuotaPolicyEvictOldest {
+		if !isDryRun(ctx) {
+			accessTokenQuotaExceeded.WithLabelValues(limit, string(QuotaPolicyReject)).Inc()
+			e.eventf(user, "AccessTokenQuotaExceeded", "Rejected a new access token of user %q for client %q over the limit of %s", token.UserName, token.ClientName, description)
+		}
+		return field.Forbidden(field.NewPath("userName"), fmt.Sprintf("the user has reached the limit of %s", description))
+	}
+	pending := evictionsFrom(ctx)
+	if pending == nil || isDryRun(ctx) {
+		return nil
+	}
+
+	sort.Slice(live, func(i, j int) bool {
+		if !live[i].CreationTimestamp.Equal(&live[j].CreationTimestamp) {
+			return live[i].CreationTimestamp.Before(&live[j].CreationTimestamp)
+		}
+		return live[i].Name < live[j].Name
+	})
+	pending.limits = append(pending.limits, limit)
+	for _, oldest := range live[:len(live)-max+1] {
+		evicted.Insert(oldest.Name)
+		pending.tokens = append(pending.tokens, eviction{token: oldest, limit: limit, user: user, description: description})
+	}
+	return nil
+}
+
+// Evict deletes the tokens Admit picked for eviction in the evictions of the context. It is
+// called once the new token was created, so that no token is evicted for a create that fails.
+// The new token is created by then, tokens that fail to be evicted are left for the next create
+// of the user to evict.
+func (e *QuotaEnforcer) Evict(ctx context.Context) {
+	pending := evictionsFrom(ctx)
+	if e == nil || pending == nil {
+		return
+	}
+	for _, limit := range pending.limits {
+		accessTokenQuotaExceeded.WithLabelValues(limit, string(QuotaPolicyEvictOldest)).Inc()
+	}
+	for _, evicted := range pending.tokens {
+		oldest := evicted.token
+		err := e.tokens.Delete(ctx, oldest.Name, metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(oldest.UID))})
+		if err != nil && !kerrors.IsNotFound(err) && !kerrors.IsConflict(err) {
+			utilruntime.HandleError(fmt.Errorf("failed to evict the oldest access token of user %q for client %q: %w", oldest.UserName, oldest.ClientName, err))
+			continue
+		}
+		evictedAccessTokens.WithLabelValues(evicted.limit).Inc()
+		e.eventf(evicted.user, "AccessTokenEvicted", "Evicted the oldest access token of user %q for client %q to stay within the limit of %s", oldest.UserName, oldest.ClientName, evicted.description)
+	}
+}
+
+func (e *QuotaEnforcer) eventf(user *corev1.ObjectReference, reason, messageFmt string, args ...interface{}) {
+	klog.Infof(messageFmt, args...)
+	if e.recorder != nil {
+		e.recorder.Eventf(user, corev1.EventTypeWarning, reason, messageFmt, args...)
+	}
+}
+
+// expired is true for tokens whose lifetime ended or, if the inactivity timeout is enforced, that
+// timed out from inactivity. They no longer count against the quota even if they were not deleted yet.
+func expired(token *oauthv1.OAuthAccessToken, now time.Time, enforceInactivityTimeout bool) bool {
+	if token.ExpiresIn > 0 && token.CreationTimestamp.Add(time.Duration(token.ExpiresIn)*time.Second).Before(now) {
+		return true
+	}
+	return enforceInactivityTimeout && token.InactivityTimeoutSeconds > 0 && token.CreationTimestamp.Add(time.Duration(token.InactivityTimeoutSeconds)*time.Second).Before(now)
+}
+
+// eviction is a token Admit picked for eviction to stay within the limit
+type eviction struct {
+	token       *oauthv1.OAuthAccessToken
+	limit       string
+	user        *corev1.ObjectReference
+	description string
+}
+
+// evictions are the tokens picked for eviction for a new token and the limits it exceeded
+type evictions struct {
+	limits []string
+	tokens []eviction
+}
+
+type evictionsKey struct{}
+
+// WithEvictions collects the tokens Admit picks for eviction, so that Evict deletes them
+// after the new token was created.
+func WithEvictions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, evictionsKey{}, &evictions{})
+}
+
+func evictionsFrom(ctx context.Context) *evictions {
+	pending, _ := ctx.Value(evictionsKey{}).(*evictions)
+	return pending
+}
+
+type dryRunKey struct{}
+
+// WithDryRun marks a create as a dry run, no tokens are evicted for it.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,171 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+func TestQuotaEnforcer(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	token := func(name, userName, clientName string, age time.Duration, expiresIn int64) *oauthv1.OAuthAccessToken {
+		return &oauthv1.OAuthAccessToken{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID("uid-" + name), CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			UserName:   userName,
+			ClientName: clientName,
+			ExpiresIn:  expiresIn,
+		}
+	}
+	timedOut := token("sha256~console-timed-out", "foo", "console", 5*time.Hour, 0)
+	timedOut.InactivityTimeoutSeconds = 600
+	existing := []*oauthv1.OAuthAccessToken{
+		token("sha256~console-old", "foo", "console", 3*time.Hour, 0),
+		token("sha256~console-new", "foo", "console", time.Hour, 0),
+		token("sha256~console-expired", "foo", "console", 4*time.Hour, 60),
+		timedOut,
+		token("sha256~cli", "foo", "cli", 2*time.Hour, 0),
+		token("sha256~bar", "bar", "console", time.Hour, 0),
+	}
+	newToken := &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~created"}, UserName: "foo", ClientName: "console"}
+
+	for _, test := range []struct {
+		name                         string
+		quota                        Quota
+		inactivityTimeoutNotEnforced bool
+		dryRun                       bool
+		expectForbidden              bool
+		expectEvicted                []string
+	}{
+		{
+			name:  "within the limits",
+			quota: Quota{PerUser: 4, PerUserClient: 3, Policy: QuotaPolicyReject},
+		},
+		{
+			name:                         "timed out tokens count if the inactivity timeout is not enforced",
+			quota:                        Quota{PerUser: 4, PerUserClient: 3, Policy: QuotaPolicyReject},
+			inactivityTimeoutNotEnforced: true,
+			expectForbidden:              true,
+		},
+		{
+			name:            "reject over the per user and client limit",
+			quota:           Quota{PerUserClient: 2, Policy: QuotaPolicyReject},
+			expectForbidden: true,
+		},
+		{
+			name:            "reject over the per user limit",
+			quota:           Quota{PerUser: 3, Policy: QuotaPolicyReject},
+			expectForbidden: true,
+		},
+		{
+			name:            "reject dry run",
+			quota:           Quota{PerUser: 3, Policy: QuotaPolicyReject},
+			dryRun:          true,
+			expectForbidden: true,
+		},
+		{
+			name:          "evict the oldest token of the client",
+			quota:         Quota{PerUserClient: 2, Policy: QuotaPolicyEvictOldest},
+			expectEvicted: []string{"sha256~console-old"},
+		},
+		{
+			name:          "evict the oldest token of the user",
+			quota:         Quota{PerUser: 2, Policy: QuotaPolicyEvictOldest},
+			expectEvicted: []string{"sha256~cli", "sha256~console-old"},
+		},
+		{
+			name:          "tokens evicted for the client count for the user",
+			quota:         Quota{PerUser: 3, PerUserClient: 2, Policy: QuotaPolicyEvictOldest},
+			expectEvicted: []string{"sha256~console-old"},
+		},
+		{
+			name:   "no eviction in dry runs",
+			quota:  Quota{PerUser: 1, Policy: QuotaPolicyEvictOldest},
+			dryRun: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+				ByUserIndexName:       ByUserIndexKeys,
+				ByUserClientIndexName: ByUserClientIndexKeys,
+			})
+			objects := []runtime.Object{}
+			for _, token := range existing {
+				if err := indexer.Add(token); err != nil {
+					t.Fatal(err)
+				}
+				objects = append(objects, token)
+			}
+			client := oauthfake.NewSimpleClientset(objects...)
+			recorder := record.NewFakeRecorder(10)
+			enforcer := &QuotaEnforcer{
+				quota:                    test.quota,
+				enforceInactivityTimeout: !test.inactivityTimeoutNotEnforced,
+				indexer:                  indexer,
+				synced:                   func() bool { return true },
+				tokens:                   client.OauthV1().OAuthAccessTokens(),
+				recorder:                 recorder,
+				clock:                    testingclock.NewFakeClock(now),
+			}
+
+			ctx := WithEvictions(context.TODO())
+			if test.dryRun {
+				ctx = WithDryRun(context.TODO())
+			}
+			errs := enforcer.Admit(ctx, newToken.DeepCopy())
+			if test.expectForbidden != (len(errs) > 0) {
+				t.Fatalf("expected forbidden=%v, got %v", test.expectForbidden, errs)
+			}
+			// tokens are only evicted once the new token was created
+			if actions := client.Actions(); len(actions) > 0 {
+				t.Fatalf("expected no tokens to be evicted before the token is created, got %v", actions)
+			}
+			if len(errs) == 0 {
+				enforcer.Evict(ctx)
+			}
+
+			evicted := []string{}
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "delete" {
+					evicted = append(evicted, action.(clienttesting.DeleteAction).GetName())
+				}
+			}
+			sort.Strings(evicted)
+			if len(test.expectEvicted) == 0 {
+				test.expectEvicted = []string{}
+			}
+			if !reflect.DeepEqual(evicted, test.expectEvicted) {
+				t.Errorf("expected %v to be evicted, got %v", test.expectEvicted, evicted)
+			}
+			if len(recorder.Events) == 0 && (test.expectForbidden && !test.dryRun || len(test.expectEvicted) > 0) {
+				t.Error("expected an event")
+			}
+		})
+	}
+}
+
+func TestNilQuotaEnforcer(t *testing.T) {
+	if enforcer := NewQuotaEnforcer(Quota{Policy: QuotaPolicyReject}, true, nil, nil, nil); enforcer != nil {
+		t.Fatalf("expected no enforcer without limits, got %#v", enforcer)
+	}
+	var enforcer *QuotaEnforcer
+	ctx := WithEvictions(context.TODO())
+	if errs := enforcer.Admit(ctx, &oauthapi.OAuthAccessToken{UserName: "foo"}); len(errs) > 0 {
+		t.Errorf("expected the nil enforcer to admit all tokens, got %v", errs)
+	}
+	enforcer.Evict(ctx)
+}
@@ -0,0 +1,83 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+type sessionStartKey struct{}
+
+// WithSessionStart lets callers within the server create a token that continues the session that
+// started at the given RFC3339 time. The session start annotation of the new token itself is
+// ignored, otherwise any creator could move the start of its session into the future.
+func WithSessionStart(ctx context.Context, sessionStart string) context.Context {
+	return context.WithValue(ctx, sessionStartKey{}, sessionStart)
+}
+
+func sessionStartFrom(ctx context.Context) (string, bool) {
+	sessionStart, ok := ctx.Value(sessionStartKey{}).(string)
+	return sessionStart, ok && len(sessionStart) > 0
+}
+
+// SessionFinder finds the session a user has with a client in an informer cache of
+// OAuthAccessTokens, so that the authorize tokens of a silent re-authorize continue it
+// instead of starting a new one.
+type SessionFinder struct {
+	indexer                  cache.Indexer
+	synced                   cache.InformerSynced
+	lifetime                 time.Duration
+	enforceInactivityTimeout bool
+	clock                    clock.Clock
+}
+
+// NewSessionFinder returns a finder of the sessions in the informer, which must have the
+// ByUserClientIndexName index. Sessions older than the absolute session lifetime are over,
+// 0 does not limit their lifetime. Tokens that timed out from inactivity only end their
+// session if the inactivity timeout is enforced.
+func NewSessionFinder(informer cache.SharedIndexInformer, lifetime time.Duration, enforceInactivityTimeout bool) *SessionFinder {
+	return &SessionFinder{
+		indexer:                  informer.GetIndexer(),
+		synced:                   informer.HasSynced,
+		lifetime:                 lifetime,
+		enforceInactivityTimeout: enforceInactivityTimeout,
+		clock:                    clock.RealClock{},
+	}
+}
+
+// SessionStart returns the earliest start of the sessions of the user with the client that have
+// live tokens and did not reach the absolute session lifetime yet, false if there is none.
+func (f *SessionFinder) SessionStart(userName, clientName string) (string, bool) {
+	if !f.synced() {
+		return "", false
+	}
+	objs, err := f.indexer.ByIndex(ByUserClientIndexName, userClientKey(userName, clientName))
+	if err != nil {
+		return "", false
+	}
+
+	now := f.clock.Now()
+	var earliest time.Time
+	var earliestValue string
+	for _, obj := range objs {
+		token := obj.(*oauthv1.OAuthAccessToken)
+		if expired(token, now, f.enforceInactivityTimeout) {
+			continue
+		}
+		value := token.Annotations[oauthapi.SessionStartAnnotation]
+		start, err := time.Parse(time.RFC3339, value)
+		if err != nil || (f.lifetime > 0 && start.Add(f.lifetime).Before(now)) {
+			continue
+		}
+		if earliest.IsZero() || start.Before(earliest) {
+			earliest, earliestValue = start, value
+		}
+	}
+	return earliestValue, len(earliestValue) > 0
+}
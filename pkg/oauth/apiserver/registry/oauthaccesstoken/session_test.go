@@ -0,0 +1,109 @@
+package oauthaccesstoken
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+func TestSessionFinder(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	token := func(name, clientName string, age time.Duration, expiresIn int64, sessionStart time.Time) *oauthv1.OAuthAccessToken {
+		return &oauthv1.OAuthAccessToken{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Annotations:       map[string]string{oauthapi.SessionStartAnnotation: sessionStart.Format(time.RFC3339)},
+			},
+			UserName:   "foo",
+			ClientName: clientName,
+			ExpiresIn:  expiresIn,
+		}
+	}
+
+	timedOut := func(token *oauthv1.OAuthAccessToken) *oauthv1.OAuthAccessToken {
+		token.InactivityTimeoutSeconds = 600
+		return token
+	}
+
+	for _, test := range []struct {
+		name                     string
+		tokens                   []*oauthv1.OAuthAccessToken
+		lifetime                 time.Duration
+		enforceInactivityTimeout bool
+		expected                 string
+	}{
+		{
+			name: "no tokens",
+		},
+		{
+			name: "earliest session of the client",
+			tokens: []*oauthv1.OAuthAccessToken{
+				token("sha256~new", "console", time.Hour, 0, now.Add(-time.Hour)),
+				token("sha256~old", "console", time.Hour, 0, now.Add(-3*time.Hour)),
+				token("sha256~cli", "cli", time.Hour, 0, now.Add(-5*time.Hour)),
+			},
+			expected: "2020-01-01T21:00:00Z",
+		},
+		{
+			name: "expired tokens do not continue their session",
+			tokens: []*oauthv1.OAuthAccessToken{
+				token("sha256~new", "console", time.Hour, 0, now.Add(-time.Hour)),
+				token("sha256~expired", "console", 3*time.Hour, 60, now.Add(-3*time.Hour)),
+			},
+			expected: "2020-01-01T23:00:00Z",
+		},
+		{
+			name: "timed out tokens do not continue their session",
+			tokens: []*oauthv1.OAuthAccessToken{
+				token("sha256~new", "console", time.Hour, 0, now.Add(-time.Hour)),
+				timedOut(token("sha256~timed-out", "console", 3*time.Hour, 0, now.Add(-3*time.Hour))),
+			},
+			enforceInactivityTimeout: true,
+			expected:                 "2020-01-01T23:00:00Z",
+		},
+		{
+			name: "timed out tokens continue their session if the inactivity timeout is not enforced",
+			tokens: []*oauthv1.OAuthAccessToken{
+				token("sha256~new", "console", time.Hour, 0, now.Add(-time.Hour)),
+				timedOut(token("sha256~timed-out", "console", 3*time.Hour, 0, now.Add(-3*time.Hour))),
+			},
+			expected: "2020-01-01T21:00:00Z",
+		},
+		{
+			name: "sessions over the absolute lifetime are not continued",
+			tokens: []*oauthv1.OAuthAccessToken{
+				token("sha256~old", "console", time.Hour, 0, now.Add(-3*time.Hour)),
+			},
+			lifetime: 2 * time.Hour,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{ByUserClientIndexName: ByUserClientIndexKeys})
+			for _, token := range test.tokens {
+				if err := indexer.Add(token); err != nil {
+					t.Fatal(err)
+				}
+			}
+			finder := &SessionFinder{
+				indexer:                  indexer,
+				synced:                   func() bool { return true },
+				lifetime:                 test.lifetime,
+				enforceInactivityTimeout: test.enforceInactivityTimeout,
+				clock:                    testingclock.NewFakeClock(now),
+			}
+
+			sessionStart, ok := finder.SessionStart("foo", "console")
+			if ok != (len(test.expected) > 0) || sessionStart != test.expected {
+				t.Errorf("expected session start %q, got %q (%v)", test.expected, sessionStart, ok)
+			}
+		})
+	}
+}
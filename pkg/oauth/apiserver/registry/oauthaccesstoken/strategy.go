@@ -2,11 +2,13 @@ package oauthaccesstoken
 
 import (
 	"context"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
 
 	scopemetadata "github.com/openshift/library-go/pkg/authorization/scopemetadata"
 	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
@@ -19,7 +21,10 @@ import (
 type strategy struct {
 	runtime.ObjectTyper
 
-	clientGetter oauthclient.Getter
+	clientGetter    oauthclient.Getter
+	authorizeTokens rest.Getter
+	quota           *QuotaEnforcer
+	lifetime        LifetimeLimit
 }
 
 var _ rest.RESTCreateStrategy = strategy{}
@@ -27,8 +32,10 @@ var _ rest.RESTUpdateStrategy = strategy{}
 var _ rest.RESTDeleteStrategy = strategy{}
 var _ rest.GarbageCollectionDeleteStrategy = strategy{}
 
-func NewStrategy(clientGetter oauthclient.Getter) strategy {
-	return strategy{ObjectTyper: serverscheme.Scheme, clientGetter: clientGetter}
+// NewStrategy returns the strategy of OAuthAccessTokens. New tokens are checked against the
+// quota, a nil quota admits all of them, and against the lifetime limit.
+func NewStrategy(clientGetter oauthclient.Getter, authorizeTokens rest.Getter, quota *QuotaEnforcer, lifetime LifetimeLimit) strategy {
+	return strategy{ObjectTyper: serverscheme.Scheme, clientGetter: clientGetter, authorizeTokens: authorizeTokens, quota: quota, lifetime: lifetime}
 }
 
 func (strategy) DefaultGarbageCollectionPolicy(ctx context.Context) rest.GarbageCollectionPolicy {
@@ -46,23 +53,86 @@ func (strategy) GenerateName(base string) string {
 	return base
 }
 
-func (strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+// PrepareForCreate carries the session start over from the authorize token the
+// token was created from, or from the caller within the server that created it.
+// Session starts set on the token itself are not trusted, tokens without a trusted
+// one start a new session. New tokens have not been used yet, and are clamped to the
+// maximum lifetime.
+func (s strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+	token := obj.(*oauthapi.OAuthAccessToken)
+	delete(token.Annotations, oauthapi.LastUsedAnnotation)
+	s.clampToClient(ctx, token)
+	if requested := token.ExpiresIn; s.lifetime.clamp(token) {
+		addLifetimeWarning(ctx, "the token was created to %s, it expires in %d seconds instead, the maximum lifetime of access tokens", describeExpiresIn(requested), token.ExpiresIn)
+	}
+	if token.Annotations == nil {
+		token.Annotations = map[string]string{}
+	}
+	token.Annotations[oauthapi.SessionStartAnnotation] = s.sessionStart(ctx, token)
+}
+
+func (s strategy) sessionStart(ctx context.Context, token *oauthapi.OAuthAccessToken) string {
+	if sessionStart, ok := sessionStartFrom(ctx); ok {
+		return sessionStart
+	}
+	if len(token.AuthorizeToken) > 0 && s.authorizeTokens != nil {
+		obj, err := s.authorizeTokens.Get(ctx, token.AuthorizeToken, &metav1.GetOptions{})
+		switch {
+		case err != nil:
+			// not logging the full error here as it would leak the token.
+			klog.V(4).Infof("Authorize token for user=%q client=%q not found, starting a new session", token.UserName, token.ClientName)
+		default:
+			if sessionStart, ok := obj.(*oauthapi.OAuthAuthorizeToken).Annotations[oauthapi.SessionStartAnnotation]; ok {
+				return sessionStart
+			}
+		}
+	}
+	return token.CreationTimestamp.UTC().Format(time.RFC3339)
 }
 
 // Validate validates a new token
 func (s strategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
 	token := obj.(*oauthapi.OAuthAccessToken)
 	validationErrors := validation.ValidateAccessToken(token)
-
-	client, err := s.clientGetter.Get(ctx, token.ClientName, metav1.GetOptions{})
-	if err != nil {
-		return append(validationErrors, field.InternalError(field.NewPath("clientName"), err))
+	validationErrors = append(validationErrors, s.lifetime.validate(token)...)
+
+	// personal access tokens are not issued through a client that could restrict their scopes,
+	// they can only be created through useroauthaccesstokenrequests
+	if token.ClientName == oauthapi.PersonalAccessTokenClientName {
+		if !isPersonalAccessToken(ctx) {
+			validationErrors = append(validationErrors, field.Invalid(field.NewPath("clientName"), token.ClientName, "is reserved for the tokens users create through useroauthaccesstokenrequests"))
+		}
+	} else {
+		client, err := s.getClient(ctx, token.ClientName)
+		if err != nil {
+			return append(validationErrors, field.InternalError(field.NewPath("clientName"), err))
+		}
+		if err := scopemetadata.ValidateScopeRestrictions(client, token.Scopes...); err != nil {
+			return append(validationErrors, field.InternalError(field.NewPath("clientName"), err))
+		}
+		if len(s.lifetime.ClientLimitPolicy) > 0 {
+			validationErrors = append(validationErrors, validateClientLimits(token, client)...)
+		}
 	}
-	if err := scopemetadata.ValidateScopeRestrictions(client, token.Scopes...); err != nil {
-		return append(validationErrors, field.InternalError(field.NewPath("clientName"), err))
+
+	// only valid tokens may evict others
+	if len(validationErrors) > 0 {
+		return validationErrors
 	}
+	return s.quota.Admit(ctx, token)
+}
+
+type personalAccessTokenKey struct{}
 
-	return validationErrors
+// WithPersonalAccessToken marks a create of a personal access token, which useroauthaccesstokenrequests
+// makes on behalf of its user. Other creates must not use the personal access token client name.
+func WithPersonalAccessToken(ctx context.Context) context.Context {
+	return context.WithValue(ctx, personalAccessTokenKey{}, true)
+}
+
+func isPersonalAccessToken(ctx context.Context) bool {
+	personal, _ := ctx.Value(personalAccessTokenKey{}).(bool)
+	return personal
 }
 
 // ValidateUpdate validates an update
@@ -81,8 +151,10 @@ func (strategy) AllowUnconditionalUpdate() bool {
 	return false
 }
 
+// WarningsOnCreate tells about lifetimes that were clamped to the limits of the client
+// or to the maximum lifetime
 func (strategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
-	return nil
+	return getLifetimeWarnings(ctx)
 }
 
 func (strategy) WarningsOnUpdate(ctx context.Context, newObj, oldObj runtime.Object) []string {
@@ -0,0 +1,125 @@
+package oauthaccesstoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// TestPrepareForCreateSessionStart asserts that the session start is carried over from the authorize token
+// or the caller within the server, and never taken from the token itself
+func TestPrepareForCreateSessionStart(t *testing.T) {
+	created := metav1.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessionStart := "2020-01-01T00:00:00Z"
+	authorizeTokens := fakeAuthorizeTokenGetter{
+		"sha256~withSessionStart": {ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{oauthapi.SessionStartAnnotation: sessionStart}}},
+	}
+
+	for _, test := range []struct {
+		name         string
+		token        *oauthapi.OAuthAccessToken
+		sessionStart string
+		expected     string
+	}{
+		{
+			name:     "no authorize token",
+			token:    &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}},
+			expected: "2020-01-02T03:04:05Z",
+		},
+		{
+			name:     "authorize token with session start",
+			token:    &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, AuthorizeToken: "sha256~withSessionStart"},
+			expected: sessionStart,
+		},
+		{
+			name:     "missing authorize token",
+			token:    &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, AuthorizeToken: "sha256~missing"},
+			expected: "2020-01-02T03:04:05Z",
+		},
+		{
+			name: "session start set by the client is ignored",
+			token: &oauthapi.OAuthAccessToken{
+				ObjectMeta:     metav1.ObjectMeta{CreationTimestamp: created, Annotations: map[string]string{oauthapi.SessionStartAnnotation: "2099-01-01T00:00:00Z"}},
+				AuthorizeToken: "sha256~withSessionStart",
+			},
+			expected: sessionStart,
+		},
+		{
+			name: "session start set by the client without authorize token is ignored",
+			token: &oauthapi.OAuthAccessToken{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created, Annotations: map[string]string{oauthapi.SessionStartAnnotation: "2099-01-01T00:00:00Z"}},
+			},
+			expected: "2020-01-02T03:04:05Z",
+		},
+		{
+			name:         "session start of the caller within the server",
+			token:        &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}},
+			sessionStart: "2019-12-31T00:00:00Z",
+			expected:     "2019-12-31T00:00:00Z",
+		},
+	} {
+		ctx := context.TODO()
+		if len(test.sessionStart) > 0 {
+			ctx = WithSessionStart(ctx, test.sessionStart)
+		}
+		s := strategy{authorizeTokens: authorizeTokens}
+		s.PrepareForCreate(ctx, test.token)
+		if got := test.token.Annotations[oauthapi.SessionStartAnnotation]; got != test.expected {
+			t.Errorf("%s: expected session start %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+type fakeAuthorizeTokenGetter map[string]*oauthapi.OAuthAuthorizeToken
+
+func (g fakeAuthorizeTokenGetter) Get(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	token, ok := g[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(oauthapi.Resource("oauthauthorizetokens"), name)
+	}
+	return token, nil
+}
+
+// TestValidatePersonalAccessToken asserts that personal access tokens do not need an OAuthClient,
+// but can only be created through useroauthaccesstokenrequests
+func TestValidatePersonalAccessToken(t *testing.T) {
+	token := &oauthapi.OAuthAccessToken{
+		ObjectMeta:  metav1.ObjectMeta{Name: "sha256~personalAccessTokenWithMinLen"},
+		ClientName:  oauthapi.PersonalAccessTokenClientName,
+		UserName:    "foo",
+		UserUID:     "bar",
+		Scopes:      []string{"user:full"},
+		RedirectURI: "urn:ietf:wg:oauth:2.0:oob",
+	}
+	s := strategy{clientGetter: fakeClientGetter{}}
+	if errs := s.Validate(WithPersonalAccessToken(context.TODO()), token); len(errs) != 0 {
+		t.Errorf("expected a valid personal access token, got %v", errs)
+	}
+	if errs := s.Validate(context.TODO(), token); len(errs) == 0 {
+		t.Error("expected personal access tokens not created through useroauthaccesstokenrequests to be invalid")
+	}
+
+	token.ClientName = "missing"
+	if errs := s.Validate(context.TODO(), token); len(errs) == 0 {
+		t.Error("expected tokens of missing clients to be invalid")
+	}
+}
+
+type fakeClientGetter []*oauthv1.OAuthClient
+
+func (g fakeClientGetter) Get(_ context.Context, name string, _ metav1.GetOptions) (*oauthv1.OAuthClient, error) {
+	for _, client := range g {
+		if client.Name == name {
+			return client, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(oauthapi.Resource("oauthclients"), name)
+}
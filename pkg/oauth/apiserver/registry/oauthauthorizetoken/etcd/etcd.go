@@ -23,9 +23,10 @@ type REST struct {
 
 var _ rest.StandardStorage = &REST{}
 
-// NewREST returns a RESTStorage object that will work against authorize tokens
-func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Getter) (*REST, error) {
-	strategy := oauthauthorizetoken.NewStrategy(clientGetter)
+// NewREST returns a RESTStorage object that will work against authorize tokens.
+// New tokens continue the sessions the sessions finder finds.
+func NewREST(optsGetter generic.RESTOptionsGetter, clientGetter oauthclient.Getter, sessions oauthauthorizetoken.SessionFinder) (*REST, error) {
+	strategy := oauthauthorizetoken.NewStrategy(clientGetter, sessions)
 	store := &registry.Store{
 		NewFunc:                   func() runtime.Object { return &oauthapi.OAuthAuthorizeToken{} },
 		NewListFunc:               func() runtime.Object { return &oauthapi.OAuthAuthorizeTokenList{} },
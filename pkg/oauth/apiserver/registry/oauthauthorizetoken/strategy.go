@@ -2,6 +2,7 @@ package oauthauthorizetoken
 
 import (
 	"context"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -20,6 +21,13 @@ type strategy struct {
 	runtime.ObjectTyper
 
 	clientGetter oauthclient.Getter
+	sessions     SessionFinder
+}
+
+// SessionFinder finds the session a user has with a client, which new authorize tokens
+// of the user for the client continue.
+type SessionFinder interface {
+	SessionStart(userName, clientName string) (string, bool)
 }
 
 var _ rest.RESTCreateStrategy = strategy{}
@@ -27,8 +35,10 @@ var _ rest.RESTUpdateStrategy = strategy{}
 var _ rest.RESTDeleteStrategy = strategy{}
 var _ rest.GarbageCollectionDeleteStrategy = strategy{}
 
-func NewStrategy(clientGetter oauthclient.Getter) strategy {
-	return strategy{ObjectTyper: serverscheme.Scheme, clientGetter: clientGetter}
+// NewStrategy returns the strategy of OAuthAuthorizeTokens. New tokens continue the session
+// the sessions finder finds, a nil finder starts a new session for every token.
+func NewStrategy(clientGetter oauthclient.Getter, sessions SessionFinder) strategy {
+	return strategy{ObjectTyper: serverscheme.Scheme, clientGetter: clientGetter, sessions: sessions}
 }
 
 func (strategy) DefaultGarbageCollectionPolicy(ctx context.Context) rest.GarbageCollectionPolicy {
@@ -46,7 +56,24 @@ func (strategy) GenerateName(base string) string {
 	return base
 }
 
-func (strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+// PrepareForCreate continues the session the user has with the client, so that silently
+// authorizing again does not start a new one. Session starts set on the token itself are
+// not trusted, tokens after an interactive login and tokens without a session to continue
+// start a new one.
+func (s strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+	token := obj.(*oauthapi.OAuthAuthorizeToken)
+	if token.Annotations == nil {
+		token.Annotations = map[string]string{}
+	}
+	interactiveLogin := token.Annotations[oauthapi.InteractiveLoginAnnotation] == "true"
+	delete(token.Annotations, oauthapi.InteractiveLoginAnnotation)
+	if s.sessions != nil && !interactiveLogin {
+		if sessionStart, ok := s.sessions.SessionStart(token.UserName, token.ClientName); ok {
+			token.Annotations[oauthapi.SessionStartAnnotation] = sessionStart
+			return
+		}
+	}
+	token.Annotations[oauthapi.SessionStartAnnotation] = token.CreationTimestamp.UTC().Format(time.RFC3339)
 }
 
 // Canonicalize normalizes the object after validation.
@@ -0,0 +1,69 @@
+package oauthauthorizetoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+type fakeSessionFinder map[string]string
+
+func (f fakeSessionFinder) SessionStart(userName, clientName string) (string, bool) {
+	sessionStart, ok := f[userName+"/"+clientName]
+	return sessionStart, ok
+}
+
+// TestPrepareForCreateSessionStart asserts that new authorize tokens continue the session of the
+// user with the client unless the user logged in interactively or sessions are not continued
+func TestPrepareForCreateSessionStart(t *testing.T) {
+	created := metav1.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessionStart := "2020-01-01T00:00:00Z"
+	sessions := fakeSessionFinder{"user/client": sessionStart}
+
+	for _, test := range []struct {
+		name        string
+		sessions    SessionFinder
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:     "session is continued",
+			sessions: sessions,
+			expected: sessionStart,
+		},
+		{
+			name:        "interactive login starts a new session",
+			sessions:    sessions,
+			annotations: map[string]string{oauthapi.InteractiveLoginAnnotation: "true"},
+			expected:    "2020-01-02T03:04:05Z",
+		},
+		{
+			name:     "sessions are not continued",
+			expected: "2020-01-02T03:04:05Z",
+		},
+		{
+			name:        "session start set by the client is ignored",
+			annotations: map[string]string{oauthapi.SessionStartAnnotation: "2019-01-01T00:00:00Z"},
+			expected:    "2020-01-02T03:04:05Z",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token := &oauthapi.OAuthAuthorizeToken{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created, Annotations: test.annotations},
+				UserName:   "user",
+				ClientName: "client",
+			}
+			NewStrategy(nil, test.sessions).PrepareForCreate(context.TODO(), token)
+			if got := token.Annotations[oauthapi.SessionStartAnnotation]; got != test.expected {
+				t.Errorf("expected session start %q, got %q", test.expected, got)
+			}
+			if _, ok := token.Annotations[oauthapi.InteractiveLoginAnnotation]; ok {
+				t.Errorf("expected the interactive login annotation not to be stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package etcd
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/sessionpolicy"
+)
+
+// REST implements a RESTStorage for session policies against etcd
+type REST struct {
+	*registry.Store
+}
+
+var _ rest.StandardStorage = &REST{}
+
+// NewREST returns a RESTStorage object that will work against session policies
+func NewREST(optsGetter generic.RESTOptionsGetter) (*REST, error) {
+	store := &registry.Store{
+		NewFunc:                   func() runtime.Object { return &sessionpolicyv1.SessionPolicy{} },
+		NewListFunc:               func() runtime.Object { return &sessionpolicyv1.SessionPolicyList{} },
+		DefaultQualifiedResource:  sessionpolicyv1.Resource("sessionpolicies"),
+		SingularQualifiedResource: sessionpolicyv1.Resource("sessionpolicy"),
+
+		TableConvertor: rest.NewDefaultTableConvertor(sessionpolicyv1.Resource("sessionpolicies")),
+
+		CreateStrategy: sessionpolicy.Strategy,
+		UpdateStrategy: sessionpolicy.Strategy,
+		DeleteStrategy: sessionpolicy.Strategy,
+	}
+
+	options := &generic.StoreOptions{RESTOptions: optsGetter}
+	if err := store.CompleteWithOptions(options); err != nil {
+		return nil, err
+	}
+
+	return &REST{store}, nil
+}
@@ -0,0 +1,71 @@
+package sessionpolicy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/validation"
+	"github.com/openshift/oauth-apiserver/pkg/serverscheme"
+)
+
+// strategy implements behavior for SessionPolicy objects
+type strategy struct {
+	runtime.ObjectTyper
+}
+
+// Strategy is the default logic that applies when creating or updating SessionPolicy
+// objects via the REST API.
+var Strategy = strategy{serverscheme.Scheme}
+
+var _ rest.RESTCreateStrategy = strategy{}
+var _ rest.RESTUpdateStrategy = strategy{}
+var _ rest.RESTDeleteStrategy = strategy{}
+
+func (strategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {}
+
+// NamespaceScoped is false for session policies
+func (strategy) NamespaceScoped() bool {
+	return false
+}
+
+func (strategy) GenerateName(base string) string {
+	return base
+}
+
+func (strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+}
+
+// Canonicalize normalizes the object after validation.
+func (strategy) Canonicalize(obj runtime.Object) {
+}
+
+// Validate validates a new session policy
+func (strategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return validation.ValidateSessionPolicy(obj.(*sessionpolicyv1.SessionPolicy))
+}
+
+// ValidateUpdate validates a session policy update
+func (strategy) ValidateUpdate(ctx context.Context, obj runtime.Object, old runtime.Object) field.ErrorList {
+	return validation.ValidateSessionPolicyUpdate(obj.(*sessionpolicyv1.SessionPolicy), old.(*sessionpolicyv1.SessionPolicy))
+}
+
+// AllowCreateOnUpdate is true, the cluster policy can be applied whether it exists or not
+func (strategy) AllowCreateOnUpdate() bool {
+	return true
+}
+
+func (strategy) AllowUnconditionalUpdate() bool {
+	return true
+}
+
+func (strategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return nil
+}
+
+func (strategy) WarningsOnUpdate(ctx context.Context, newObj, oldObj runtime.Object) []string {
+	return nil
+}
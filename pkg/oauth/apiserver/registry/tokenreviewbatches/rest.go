@@ -0,0 +1,128 @@
+package tokenreviewbatches
+
+import (
+	"context"
+	"fmt"
+
+	kauthenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/util/workqueue"
+	kauthinternal "k8s.io/kubernetes/pkg/apis/authentication"
+	kauthv1internal "k8s.io/kubernetes/pkg/apis/authentication/v1"
+	"k8s.io/kubernetes/pkg/registry/authentication/tokenreview"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
+
+	tokenreviewbatchv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewbatch/v1"
+	tokenreviews "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviews"
+)
+
+const (
+	// MaxReviews is the largest number of tokens a single TokenReviewBatch may hold
+	MaxReviews = 100
+	// maxConcurrentReviews caps the reviews of a single batch that run at the same time
+	maxConcurrentReviews = 10
+)
+
+// REST reviews several tokens at once using the same authenticators as the tokenreviews resource
+type REST struct {
+	tokenReviews   *tokenreview.REST
+	failureLimiter *tokenreviews.FailureLimiter
+}
+
+var _ rest.SingularNameProvider = &REST{}
+var _ rest.Storage = &REST{}
+var _ rest.Creater = &REST{}
+
+// NewREST returns the tokenreviewbatches storage. Failed reviews count against the same
+// failureLimiter as those of the tokenreviews resource, it may be nil to not limit them.
+func NewREST(tokenAuthenticator authenticator.Request, failureLimiter *tokenreviews.FailureLimiter) *REST {
+	return &REST{tokenReviews: tokenreview.NewREST(tokenAuthenticator, []string{}), failureLimiter: failureLimiter}
+}
+
+func (r *REST) New() runtime.Object {
+	return &tokenreviewbatchv1.TokenReviewBatch{}
+}
+
+func (r *REST) Destroy() {
+	r.tokenReviews.Destroy()
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return oauthv1.GroupVersion.WithKind("TokenReviewBatch")
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (r *REST) GetSingularName() string {
+	return "tokenreviewbatch"
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, validateObj rest.ValidateObjectFunc, createOptions *metav1.CreateOptions) (runtime.Object, error) {
+	batch, ok := obj.(*tokenreviewbatchv1.TokenReviewBatch)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a TokenReviewBatch: %#v", obj))
+	}
+	if len(batch.Spec.Reviews) == 0 {
+		return nil, apierrors.NewBadRequest("spec.reviews is required for TokenReviewBatch")
+	}
+	if len(batch.Spec.Reviews) > MaxReviews {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("spec.reviews must not hold more than %d tokens", MaxReviews))
+	}
+
+	if validateObj != nil {
+		if err := validateObj(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]kauthenticationv1.TokenReviewStatus, len(batch.Spec.Reviews))
+	workqueue.ParallelizeUntil(ctx, maxConcurrentReviews, len(batch.Spec.Reviews), func(i int) {
+		statuses[i] = r.review(ctx, batch.Spec.Reviews[i])
+	})
+
+	bootstrapUserAuthenticated := false
+	for i, status := range statuses {
+		if status.Authenticated {
+			bootstrapUserAuthenticated = bootstrapUserAuthenticated || status.User.Username == bootstrap.BootstrapUser
+			continue
+		}
+		if throttled, retryAfter := r.failureLimiter.Failed(tokenreviews.RequestingUser(ctx), batch.Annotations[tokenreviews.ForwardedClientAnnotation]); throttled {
+			statuses[i] = kauthenticationv1.TokenReviewStatus{Error: tokenreviews.TooManyFailures(retryAfter).Error()}
+		}
+	}
+	if bootstrapUserAuthenticated {
+		audit.AddAuditAnnotation(ctx, tokenreviews.BootstrapUserAuthenticatedAnnotation, "true")
+	}
+
+	batch.Status.Reviews = statuses
+	return batch, nil
+}
+
+// review runs a single token review. Failures only affect the status of that review.
+func (r *REST) review(ctx context.Context, spec kauthenticationv1.TokenReviewSpec) kauthenticationv1.TokenReviewStatus {
+	tokenReviewInternal := &kauthinternal.TokenReview{}
+	if err := kauthv1internal.Convert_v1_TokenReview_To_authentication_TokenReview(&kauthenticationv1.TokenReview{Spec: spec}, tokenReviewInternal, nil); err != nil {
+		return kauthenticationv1.TokenReviewStatus{Error: err.Error()}
+	}
+
+	result, err := r.tokenReviews.Create(ctx, tokenReviewInternal, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return kauthenticationv1.TokenReviewStatus{Error: err.Error()}
+	}
+
+	tokenReview := &kauthenticationv1.TokenReview{}
+	if err := kauthv1internal.Convert_authentication_TokenReview_To_v1_TokenReview(result.(*kauthinternal.TokenReview), tokenReview, nil); err != nil {
+		return kauthenticationv1.TokenReviewStatus{Error: err.Error()}
+	}
+	return tokenReview.Status
+}
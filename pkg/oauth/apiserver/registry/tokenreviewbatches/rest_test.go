@@ -0,0 +1,109 @@
+package tokenreviewbatches
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	kauthenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	tokenreviewbatchv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewbatch/v1"
+	tokenreviews "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviews"
+)
+
+func TestCreate(t *testing.T) {
+	r := NewREST(authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		switch token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); token {
+		case "valid":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "foo", UID: "bar"}}, true, nil
+		case "broken":
+			return nil, false, errors.New("lookup failed")
+		default:
+			return nil, false, nil
+		}
+	}), nil)
+
+	batch := &tokenreviewbatchv1.TokenReviewBatch{
+		Spec: tokenreviewbatchv1.TokenReviewBatchSpec{
+			Reviews: []kauthenticationv1.TokenReviewSpec{
+				{Token: "valid"},
+				{Token: "unknown"},
+				{Token: "broken"},
+				{},
+			},
+		},
+	}
+	obj, err := r.Create(context.TODO(), batch, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := obj.(*tokenreviewbatchv1.TokenReviewBatch).Status.Reviews
+	if len(statuses) != 4 {
+		t.Fatalf("expected a status per review, got %#v", statuses)
+	}
+	if !statuses[0].Authenticated || statuses[0].User.Username != "foo" || statuses[0].User.UID != "bar" {
+		t.Errorf("expected the first token to be authenticated, got %#v", statuses[0])
+	}
+	if statuses[1].Authenticated || len(statuses[1].Error) != 0 {
+		t.Errorf("expected the second token to be unauthenticated, got %#v", statuses[1])
+	}
+	if statuses[2].Authenticated || statuses[2].Error != "lookup failed" {
+		t.Errorf("expected the third token to fail, got %#v", statuses[2])
+	}
+	if statuses[3].Authenticated || len(statuses[3].Error) == 0 {
+		t.Errorf("expected the empty token to be rejected, got %#v", statuses[3])
+	}
+}
+
+func TestCreateLimits(t *testing.T) {
+	r := NewREST(nil, nil)
+
+	tooMany := make([]kauthenticationv1.TokenReviewSpec, MaxReviews+1)
+	for _, reviews := range [][]kauthenticationv1.TokenReviewSpec{nil, tooMany} {
+		batch := &tokenreviewbatchv1.TokenReviewBatch{Spec: tokenreviewbatchv1.TokenReviewBatchSpec{Reviews: reviews}}
+		if _, err := r.Create(context.TODO(), batch, nil, &metav1.CreateOptions{}); !apierrors.IsBadRequest(err) {
+			t.Errorf("expected a bad request for %d reviews, got %v", len(reviews), err)
+		}
+	}
+}
+
+func TestCreateThrottlesOnlyFailures(t *testing.T) {
+	r := NewREST(authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		if req.Header.Get("Authorization") == "Bearer valid" {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "foo"}}, true, nil
+		}
+		return nil, false, nil
+	}), tokenreviews.NewFailureLimiter(tokenreviews.FailureLimits{ClientRate: 0.001, ClientBurst: 1, LockoutDuration: time.Minute}, nil))
+	ctx := genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "gateway"})
+	review := func(tokens ...string) []kauthenticationv1.TokenReviewStatus {
+		batch := &tokenreviewbatchv1.TokenReviewBatch{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{tokenreviews.ForwardedClientAnnotation: "10.0.0.1"}}}
+		for _, token := range tokens {
+			batch.Spec.Reviews = append(batch.Spec.Reviews, kauthenticationv1.TokenReviewSpec{Token: token})
+		}
+		obj, err := r.Create(ctx, batch, nil, &metav1.CreateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return obj.(*tokenreviewbatchv1.TokenReviewBatch).Status.Reviews
+	}
+
+	if statuses := review("invalid"); statuses[0].Authenticated || len(statuses[0].Error) > 0 {
+		t.Fatalf("expected the first failure to be answered, got %#v", statuses[0])
+	}
+	statuses := review("valid", "invalid")
+	if !statuses[0].Authenticated {
+		t.Errorf("expected successes to be answered while locked out, got %#v", statuses[0])
+	}
+	if statuses[1].Authenticated || !strings.Contains(statuses[1].Error, "too many failed token reviews") {
+		t.Errorf("expected the second failure to be throttled, got %#v", statuses[1])
+	}
+}
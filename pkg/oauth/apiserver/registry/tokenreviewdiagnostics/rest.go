@@ -0,0 +1,91 @@
+package tokenreviewdiagnostics
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	tokenreviewdiagnosticv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewdiagnostic/v1"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+)
+
+// TokenDiagnoser diagnoses OAuth access tokens, usually a *tokenvalidation.TokenDiagnoser
+type TokenDiagnoser interface {
+	DiagnoseToken(ctx context.Context, token string, audiences []string) *tokenvalidation.Diagnosis
+	DiagnoseTokenName(ctx context.Context, name string, audiences []string) *tokenvalidation.Diagnosis
+}
+
+// REST explains why OAuth access tokens fail to authenticate. The reasons are not masked
+// like in the tokenreviews resource, so unlike tokenreviews it is not allowed by the
+// hard-coded authorizer and creating it must be granted explicitly.
+type REST struct {
+	diagnoser TokenDiagnoser
+}
+
+var _ rest.SingularNameProvider = &REST{}
+var _ rest.Storage = &REST{}
+var _ rest.Creater = &REST{}
+
+func NewREST(diagnoser TokenDiagnoser) *REST {
+	return &REST{diagnoser: diagnoser}
+}
+
+func (r *REST) New() runtime.Object {
+	return &tokenreviewdiagnosticv1.TokenReviewDiagnostic{}
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return oauthv1.GroupVersion.WithKind("TokenReviewDiagnostic")
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (r *REST) GetSingularName() string {
+	return "tokenreviewdiagnostic"
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, validateObj rest.ValidateObjectFunc, createOptions *metav1.CreateOptions) (runtime.Object, error) {
+	diagnostic, ok := obj.(*tokenreviewdiagnosticv1.TokenReviewDiagnostic)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a TokenReviewDiagnostic: %#v", obj))
+	}
+	spec := diagnostic.Spec
+	if (len(spec.Token) == 0) == (len(spec.TokenName) == 0) {
+		return nil, apierrors.NewBadRequest("exactly one of spec.token and spec.tokenName is required for TokenReviewDiagnostic")
+	}
+
+	if validateObj != nil {
+		if err := validateObj(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	var diagnosis *tokenvalidation.Diagnosis
+	if len(spec.Token) > 0 {
+		diagnosis = r.diagnoser.DiagnoseToken(ctx, spec.Token, spec.Audiences)
+	} else {
+		diagnosis = r.diagnoser.DiagnoseTokenName(ctx, spec.TokenName, spec.Audiences)
+	}
+
+	// do not echo the token back
+	diagnostic.Spec.Token = ""
+	diagnostic.Status = tokenreviewdiagnosticv1.TokenReviewDiagnosticStatus{
+		Authenticated: diagnosis.Authenticated,
+		FailedStep:    diagnosis.Step,
+		Reason:        diagnosis.Reason,
+		TokenName:     diagnosis.TokenName,
+		UserName:      diagnosis.UserName,
+	}
+	return diagnostic, nil
+}
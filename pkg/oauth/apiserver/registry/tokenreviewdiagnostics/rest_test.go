@@ -0,0 +1,56 @@
+package tokenreviewdiagnostics
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tokenreviewdiagnosticv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/tokenreviewdiagnostic/v1"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+)
+
+type fakeDiagnoser struct{}
+
+func (fakeDiagnoser) DiagnoseToken(_ context.Context, token string, _ []string) *tokenvalidation.Diagnosis {
+	return &tokenvalidation.Diagnosis{Step: tokenvalidation.DiagnosisStepTokenLookup, Reason: "not found", TokenName: "sha256~" + token}
+}
+
+func (fakeDiagnoser) DiagnoseTokenName(_ context.Context, name string, _ []string) *tokenvalidation.Diagnosis {
+	return &tokenvalidation.Diagnosis{Authenticated: true, TokenName: name, UserName: "foo"}
+}
+
+func TestCreate(t *testing.T) {
+	r := NewREST(fakeDiagnoser{})
+
+	obj, err := r.Create(context.TODO(), &tokenreviewdiagnosticv1.TokenReviewDiagnostic{
+		Spec: tokenreviewdiagnosticv1.TokenReviewDiagnosticSpec{Token: "secret"},
+	}, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostic := obj.(*tokenreviewdiagnosticv1.TokenReviewDiagnostic)
+	if len(diagnostic.Spec.Token) != 0 {
+		t.Error("expected the token to be removed from the response")
+	}
+	if expected := (tokenreviewdiagnosticv1.TokenReviewDiagnosticStatus{FailedStep: tokenvalidation.DiagnosisStepTokenLookup, Reason: "not found", TokenName: "sha256~secret"}); diagnostic.Status != expected {
+		t.Errorf("expected status %#v, got %#v", expected, diagnostic.Status)
+	}
+
+	obj, err = r.Create(context.TODO(), &tokenreviewdiagnosticv1.TokenReviewDiagnostic{
+		Spec: tokenreviewdiagnosticv1.TokenReviewDiagnosticSpec{TokenName: "sha256~name"},
+	}, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (tokenreviewdiagnosticv1.TokenReviewDiagnosticStatus{Authenticated: true, TokenName: "sha256~name", UserName: "foo"}); obj.(*tokenreviewdiagnosticv1.TokenReviewDiagnostic).Status != expected {
+		t.Errorf("expected status %#v, got %#v", expected, obj.(*tokenreviewdiagnosticv1.TokenReviewDiagnostic).Status)
+	}
+
+	for _, spec := range []tokenreviewdiagnosticv1.TokenReviewDiagnosticSpec{{}, {Token: "secret", TokenName: "sha256~name"}} {
+		if _, err := r.Create(context.TODO(), &tokenreviewdiagnosticv1.TokenReviewDiagnostic{Spec: spec}, nil, &metav1.CreateOptions{}); !apierrors.IsBadRequest(err) {
+			t.Errorf("expected a bad request for %#v, got %v", spec, err)
+		}
+	}
+}
@@ -3,22 +3,33 @@ package etcd
 import (
 	"context"
 	"fmt"
+	"math"
+	"time"
 
 	kauthenticationv1 "k8s.io/api/authentication/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/audit"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	kauthinternal "k8s.io/kubernetes/pkg/apis/authentication"
 	kauthv1internal "k8s.io/kubernetes/pkg/apis/authentication/v1"
 	"k8s.io/kubernetes/pkg/registry/authentication/tokenreview"
+
+	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
 )
 
+// BootstrapUserAuthenticatedAnnotation is the audit annotation added to token reviews
+// that authenticated the bootstrap user, so that any use of it can be flagged.
+const BootstrapUserAuthenticatedAnnotation = "authentication.openshift.io/bootstrap-user-authenticated"
+
 // REST object wraps the kube TokenReviews REST so that we can use it with our own API path
 type REST struct {
-	wrapped *tokenreview.REST
+	wrapped        *tokenreview.REST
+	failureLimiter *FailureLimiter
 }
 
 var _ rest.SingularNameProvider = &REST{}
@@ -29,8 +40,10 @@ func (r *REST) New() runtime.Object {
 	return &kauthenticationv1.TokenReview{}
 }
 
-func NewREST(tokenAuthenticator authenticator.Request) (*REST, error) {
-	return &REST{wrapped: tokenreview.NewREST(tokenAuthenticator, []string{})}, nil
+// NewREST returns the tokenreviews storage. Failed reviews are limited by failureLimiter,
+// which may be nil to not limit them.
+func NewREST(tokenAuthenticator authenticator.Request, failureLimiter *FailureLimiter) (*REST, error) {
+	return &REST{wrapped: tokenreview.NewREST(tokenAuthenticator, []string{}), failureLimiter: failureLimiter}, nil
 }
 
 func (r *REST) Destroy() {
@@ -60,5 +73,37 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, validateObj rest.
 	if err := kauthv1internal.Convert_v1_TokenReview_To_authentication_TokenReview(tokenReview, tokenReviewInternal, nil); err != nil {
 		return nil, apierrors.NewInternalError(fmt.Errorf("failed to convert %#v to internal TokenReview: %v", obj, err))
 	}
-	return r.wrapped.Create(ctx, tokenReviewInternal, validateObj, createOptions)
+	result, err := r.wrapped.Create(ctx, tokenReviewInternal, validateObj, createOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	review, ok := result.(*kauthinternal.TokenReview)
+	if !ok {
+		return result, nil
+	}
+	if review.Status.Authenticated && review.Status.User.Username == bootstrap.BootstrapUser {
+		audit.AddAuditAnnotation(ctx, BootstrapUserAuthenticatedAnnotation, "true")
+	}
+	if !review.Status.Authenticated {
+		if throttled, retryAfter := r.failureLimiter.Failed(RequestingUser(ctx), tokenReview.Annotations[ForwardedClientAnnotation]); throttled {
+			return nil, TooManyFailures(retryAfter)
+		}
+	}
+
+	return result, nil
+}
+
+// TooManyFailures is the error of a failed review rejected because its source exceeded its failure limit
+func TooManyFailures(retryAfter time.Duration) *apierrors.StatusError {
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	return apierrors.NewTooManyRequests(fmt.Sprintf("too many failed token reviews, retry after %ds", retryAfterSeconds), retryAfterSeconds)
+}
+
+// RequestingUser returns the name of the user requesting a review, whose failed reviews are limited
+func RequestingUser(ctx context.Context) string {
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		return user.GetName()
+	}
+	return ""
 }
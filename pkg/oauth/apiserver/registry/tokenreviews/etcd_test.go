@@ -0,0 +1,45 @@
+package etcd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	kauthenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
+)
+
+func TestCreateAnnotatesBootstrapUser(t *testing.T) {
+	r, err := NewREST(authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		name := "foo"
+		if req.Header.Get("Authorization") == "Bearer bootstrap" {
+			name = bootstrap.BootstrapUser
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: name}}, true, nil
+	}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		token    string
+		expected bool
+	}{
+		{token: "bootstrap", expected: true},
+		{token: "other", expected: false},
+	} {
+		ctx := audit.WithAuditContext(context.TODO())
+		if _, err := r.Create(ctx, &kauthenticationv1.TokenReview{Spec: kauthenticationv1.TokenReviewSpec{Token: test.token}}, nil, &metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		annotation, ok := audit.AuditContextFrom(ctx).GetEventAnnotation(BootstrapUserAuthenticatedAnnotation)
+		if ok != test.expected || (ok && annotation != "true") {
+			t.Errorf("token %q: expected annotation=%v, got %q %v", test.token, test.expected, annotation, ok)
+		}
+	}
+}
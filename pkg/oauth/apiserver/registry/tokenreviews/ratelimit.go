@@ -0,0 +1,186 @@
+package etcd
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// ForwardedClientAnnotation may be set on a TokenReview by the requesting user to identify
+// the client it reviews the token for, e.g. its IP address. Failed reviews are then also
+// limited per forwarded client.
+const ForwardedClientAnnotation = "authentication.openshift.io/forwarded-client"
+
+const (
+	sourceUser   = "user"
+	sourceClient = "client"
+
+	// pruneInterval is how often sources that no longer need to be tracked are dropped
+	pruneInterval = time.Minute
+)
+
+var (
+	throttledTokenReviews = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "openshift_oauth_apiserver_token_reviews_throttled_total",
+			Help:           "Number of failed token reviews that were rejected because their source exceeded its failure limit, by kind of source.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"source"},
+	)
+	tokenReviewLockouts = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "openshift_oauth_apiserver_token_review_lockouts_total",
+			Help:           "Number of times a source of token reviews was locked out after exceeding its failure limit, by kind of source.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"source"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(throttledTokenReviews, tokenReviewLockouts)
+}
+
+// FailureLimits configures the limits of failed token reviews. A rate of 0 disables the limit.
+type FailureLimits struct {
+	// UserRate and UserBurst limit the failed reviews per second of each requesting user,
+	// across the clients it forwards in the ForwardedClientAnnotation.
+	UserRate  float64
+	UserBurst int
+	// ClientRate and ClientBurst limit the failed reviews per second of each client
+	// a requesting user forwards in the ForwardedClientAnnotation.
+	ClientRate  float64
+	ClientBurst int
+	// LockoutDuration is how long failed reviews of a source that exceeded its limit are rejected.
+	LockoutDuration time.Duration
+}
+
+// Enabled returns whether any failure limit is set.
+func (l FailureLimits) Enabled() bool {
+	return l.UserRate > 0 || l.ClientRate > 0
+}
+
+// FailureLimiter tracks failed token reviews by source to stop token guessing. Only
+// failures are counted and limited, successful reviews are never slowed down. Reviews
+// without a forwarded client are not limited: their requesting user is usually the
+// kube-apiserver reviewing the tokens of all users, locking it out would lock out everybody.
+type FailureLimiter struct {
+	limits   FailureLimits
+	recorder record.EventRecorder
+	clock    clock.Clock
+
+	lock      sync.Mutex
+	sources   map[string]*failureSource
+	lastPrune time.Time
+}
+
+type failureSource struct {
+	limiter     *rate.Limiter
+	lockedUntil time.Time
+	// fullAt is when the limiter is back to its full burst and the source can be forgotten
+	fullAt time.Time
+}
+
+// NewFailureLimiter returns a limiter for the limits, or nil if no limit is set.
+// The nil limiter allows everything. Lockouts are reported as events by the recorder.
+func NewFailureLimiter(limits FailureLimits, recorder record.EventRecorder) *FailureLimiter {
+	if !limits.Enabled() {
+		return nil
+	}
+	return &FailureLimiter{
+		limits:   limits,
+		recorder: recorder,
+		clock:    clock.RealClock{},
+		sources:  map[string]*failureSource{},
+	}
+}
+
+// Failed records a failed review of userName on behalf of forwardedClient. It returns whether
+// the failure must be rejected and how long the lockout lasts. Failures without a forwarded
+// client are never rejected.
+func (l *FailureLimiter) Failed(userName, forwardedClient string) (bool, time.Duration) {
+	if l == nil {
+		return false, 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	l.prune(now)
+
+	if len(forwardedClient) == 0 {
+		return false, 0
+	}
+
+	var retryAfter time.Duration
+	if l.limits.UserRate > 0 {
+		if wait := l.fail(now, sourceUser, userName, l.limits.UserRate, l.limits.UserBurst); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if l.limits.ClientRate > 0 {
+		if wait := l.fail(now, sourceClient, userName+"/"+forwardedClient, l.limits.ClientRate, l.limits.ClientBurst); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	return retryAfter > 0, retryAfter
+}
+
+// fail counts a failure of a source and returns the remaining lockout of the source, if any
+func (l *FailureLimiter) fail(now time.Time, kind, name string, limit float64, burst int) time.Duration {
+	key := kind + ":" + name
+	source, ok := l.sources[key]
+	if !ok {
+		source = &failureSource{limiter: rate.NewLimiter(rate.Limit(limit), burst)}
+		l.sources[key] = source
+	}
+	source.fullAt = now.Add(time.Duration(float64(burst) / limit * float64(time.Second)))
+
+	if now.Before(source.lockedUntil) {
+		throttledTokenReviews.WithLabelValues(kind).Inc()
+		return source.lockedUntil.Sub(now)
+	}
+	if source.limiter.AllowN(now, 1) {
+		return 0
+	}
+
+	source.lockedUntil = now.Add(l.limits.LockoutDuration)
+	if source.fullAt.Before(source.lockedUntil) {
+		source.fullAt = source.lockedUntil
+	}
+	throttledTokenReviews.WithLabelValues(kind).Inc()
+	tokenReviewLockouts.WithLabelValues(kind).Inc()
+	klog.Warningf("Failed token reviews of %s %q exceeded %v per second, rejecting them for %s", kind, name, limit, l.limits.LockoutDuration)
+	if l.recorder != nil {
+		l.recorder.Eventf(&corev1.ObjectReference{Kind: "TokenReview", APIVersion: "oauth.openshift.io/v1"}, corev1.EventTypeWarning, "TokenReviewsThrottled",
+			"Failed token reviews of %s %q exceeded %v per second, rejecting them for %s", kind, name, limit, l.limits.LockoutDuration)
+	}
+	if l.limits.LockoutDuration <= 0 {
+		// without a lockout only the failure exceeding the limit is rejected
+		return time.Second
+	}
+	return l.limits.LockoutDuration
+}
+
+// prune drops the sources whose limiter is full again, they are no different from new ones
+func (l *FailureLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, source := range l.sources {
+		if now.After(source.fullAt) {
+			delete(l.sources, key)
+		}
+	}
+}
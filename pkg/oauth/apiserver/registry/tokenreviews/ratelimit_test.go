@@ -0,0 +1,113 @@
+package etcd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	kauthenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/record"
+	kauthinternal "k8s.io/kubernetes/pkg/apis/authentication"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestFailureLimiter(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	limiter := NewFailureLimiter(FailureLimits{UserRate: 1, UserBurst: 2, ClientRate: 1, ClientBurst: 1, LockoutDuration: time.Minute}, recorder)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	limiter.clock = fakeClock
+
+	// the kube-apiserver reviews the tokens of all users without forwarding clients
+	for i := 0; i < 10; i++ {
+		if throttled, _ := limiter.Failed("kube-apiserver", ""); throttled {
+			t.Fatalf("failure %d without a forwarded client should not be throttled", i)
+		}
+	}
+	if len(limiter.sources) != 0 {
+		t.Errorf("expected no sources to be tracked without forwarded clients, got %d", len(limiter.sources))
+	}
+
+	// forwarded clients are limited on their own
+	if throttled, _ := limiter.Failed("gateway", "10.0.0.1"); throttled {
+		t.Fatal("the first failure of the client should not be throttled")
+	}
+	throttled, retryAfter := limiter.Failed("gateway", "10.0.0.1")
+	if !throttled || retryAfter != time.Minute {
+		t.Fatalf("expected the client to be locked out for a minute, got %v %v", throttled, retryAfter)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "TokenReviewsThrottled") {
+		t.Errorf("unexpected event %q", event)
+	}
+
+	// failures of forwarded clients count against the requesting user as well
+	if throttled, _ := limiter.Failed("gateway", "10.0.0.2"); !throttled {
+		t.Fatal("expected the requesting user to be locked out after its burst")
+	}
+
+	// the lockout holds although the rate would allow failures again
+	fakeClock.Step(30 * time.Second)
+	if throttled, retryAfter := limiter.Failed("gateway", "10.0.0.3"); !throttled || retryAfter != 30*time.Second {
+		t.Fatalf("expected the lockout to last, got %v %v", throttled, retryAfter)
+	}
+	if throttled, _ := limiter.Failed("other", "10.0.0.1"); throttled {
+		t.Fatal("other users must not be locked out")
+	}
+
+	fakeClock.Step(31 * time.Second)
+	if throttled, _ := limiter.Failed("gateway", "10.0.0.4"); throttled {
+		t.Fatal("the lockout should be over")
+	}
+
+	// sources are forgotten once their limiter is full again
+	fakeClock.Step(time.Hour)
+	limiter.Failed("other", "10.0.0.9")
+	if len(limiter.sources) != 2 {
+		t.Errorf("expected only the user and client of the last failure to be tracked, got %d", len(limiter.sources))
+	}
+}
+
+func TestCreateThrottlesOnlyFailures(t *testing.T) {
+	r, err := NewREST(authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		if req.Header.Get("Authorization") == "Bearer valid" {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "foo"}}, true, nil
+		}
+		return nil, false, nil
+	}), NewFailureLimiter(FailureLimits{UserRate: 0.001, UserBurst: 1, LockoutDuration: time.Minute}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := func(userName, forwardedClient, token string) (*kauthinternal.TokenReview, error) {
+		ctx := genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: userName})
+		tokenReview := &kauthenticationv1.TokenReview{Spec: kauthenticationv1.TokenReviewSpec{Token: token}}
+		if len(forwardedClient) > 0 {
+			tokenReview.Annotations = map[string]string{ForwardedClientAnnotation: forwardedClient}
+		}
+		result, err := r.Create(ctx, tokenReview, nil, &metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return result.(*kauthinternal.TokenReview), nil
+	}
+
+	if result, err := review("gateway", "10.0.0.1", "invalid"); err != nil || result.Status.Authenticated {
+		t.Fatalf("expected the first failure to be answered, got %#v %v", result, err)
+	}
+	if _, err := review("gateway", "10.0.0.1", "invalid"); !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expected the second failure to be throttled, got %v", err)
+	}
+	if result, err := review("gateway", "10.0.0.1", "valid"); err != nil || !result.Status.Authenticated {
+		t.Fatalf("expected successes to be answered while locked out, got %#v %v", result, err)
+	}
+	for i := 0; i < 3; i++ {
+		if result, err := review("kube-apiserver", "", "invalid"); err != nil || result.Status.Authenticated {
+			t.Fatalf("expected failures without a forwarded client to be answered, got %#v %v", result, err)
+		}
+	}
+}
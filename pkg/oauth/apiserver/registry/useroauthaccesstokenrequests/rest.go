@@ -0,0 +1,79 @@
+package useroauthaccesstokenrequests
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	useroauthaccesstokenrequestv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/useroauthaccesstokenrequest/v1"
+)
+
+// TokenCreator creates the personal access tokens, usually the useroauthaccesstokens storage
+type TokenCreator interface {
+	CreateToken(ctx context.Context, scopes []string, expiresIn int64, options *metav1.CreateOptions) (*oauthapi.UserOAuthAccessToken, string, error)
+}
+
+// REST creates personal access tokens for the user of the request. The tokens are only
+// returned in the status of the created UserOAuthAccessTokenRequest, which is never stored.
+type REST struct {
+	tokens TokenCreator
+}
+
+var _ rest.SingularNameProvider = &REST{}
+var _ rest.Storage = &REST{}
+var _ rest.Creater = &REST{}
+
+func NewREST(tokens TokenCreator) *REST {
+	return &REST{tokens: tokens}
+}
+
+func (r *REST) New() runtime.Object {
+	return &useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest{}
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return oauthv1.GroupVersion.WithKind("UserOAuthAccessTokenRequest")
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (r *REST) GetSingularName() string {
+	return "useroauthaccesstokenrequest"
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, validateObj rest.ValidateObjectFunc, createOptions *metav1.CreateOptions) (runtime.Object, error) {
+	request, ok := obj.(*useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a UserOAuthAccessTokenRequest: %#v", obj))
+	}
+	if validateObj != nil {
+		if err := validateObj(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	created, token, err := r.tokens.CreateToken(ctx, request.Spec.Scopes, request.Spec.ExpiresIn, createOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Spec.Scopes = created.Scopes
+	request.Status = useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequestStatus{
+		Token:     token,
+		TokenName: created.Name,
+		ExpiresIn: created.ExpiresIn,
+	}
+	return request, nil
+}
@@ -0,0 +1,60 @@
+package useroauthaccesstokenrequests
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	useroauthaccesstokenrequestv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/useroauthaccesstokenrequest/v1"
+)
+
+type fakeTokenCreator struct {
+	err error
+}
+
+func (f fakeTokenCreator) CreateToken(_ context.Context, scopes []string, expiresIn int64, _ *metav1.CreateOptions) (*oauthapi.UserOAuthAccessToken, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"user:full"}
+	}
+	return &oauthapi.UserOAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~hash"}, Scopes: scopes, ExpiresIn: expiresIn}, "sha256~secret", nil
+}
+
+func TestCreate(t *testing.T) {
+	r := NewREST(fakeTokenCreator{})
+
+	obj, err := r.Create(context.TODO(), &useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest{
+		Spec: useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequestSpec{ExpiresIn: 600},
+	}, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := obj.(*useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest)
+	if expected := []string{"user:full"}; !reflect.DeepEqual(request.Spec.Scopes, expected) {
+		t.Errorf("expected the scopes of the token %v, got %v", expected, request.Spec.Scopes)
+	}
+	if expected := (useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequestStatus{Token: "sha256~secret", TokenName: "sha256~hash", ExpiresIn: 600}); request.Status != expected {
+		t.Errorf("expected status %#v, got %#v", expected, request.Status)
+	}
+
+	// admission sees the request before any token is created
+	rejected := apierrors.NewForbidden(oauthv1.Resource("useroauthaccesstokenrequests"), "", fmt.Errorf("rejected"))
+	r = NewREST(fakeTokenCreator{err: apierrors.NewInternalError(fmt.Errorf("failed"))})
+	if _, err := r.Create(context.TODO(), &useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest{}, func(context.Context, runtime.Object) error { return rejected }, &metav1.CreateOptions{}); err != rejected {
+		t.Errorf("expected the validation error, got %v", err)
+	}
+
+	if _, err := r.Create(context.TODO(), &useroauthaccesstokenrequestv1.UserOAuthAccessTokenRequest{}, nil, &metav1.CreateOptions{}); !apierrors.IsInternalError(err) {
+		t.Errorf("expected the error of the token creator, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package delegate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	"github.com/openshift/api/oauth"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+const (
+	// personalAccessTokenRedirectURI is the redirect URI of the tokens users create themselves,
+	// they are never redirected anywhere
+	personalAccessTokenRedirectURI = "urn:ietf:wg:oauth:2.0:oob"
+
+	userFullScope = "user:full"
+	// tokenBytes is the amount of random bytes in a generated token
+	tokenBytes = 32
+)
+
+// CreateToken generates a token for the user of the request and returns it with its
+// UserOAuthAccessToken. The scopes must be a subset of the scopes the user made the request
+// with, and the lifetime in seconds is capped by the maximum lifetime and the session policy.
+// Tokens created with an OAuth access token continue its session, so that they end with it
+// at the absolute session lifetime. Only the hash of the token is stored, the token is only
+// returned to the useroauthaccesstokenrequests storage.
+func (r *REST) CreateToken(ctx context.Context, requestedScopes []string, requestedExpiresIn int64, options *metav1.CreateOptions) (*oauthapi.UserOAuthAccessToken, string, error) {
+	userInfo, ok := apirequest.UserFrom(ctx)
+	if !ok || len(userInfo.GetName()) == 0 {
+		return nil, "", errors.NewForbidden(oauth.Resource("useroauthaccesstokenrequests"), "", fmt.Errorf("no user in the request"))
+	}
+
+	user, err := r.users.Get(ctx, userInfo.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, "", errors.NewForbidden(oauth.Resource("useroauthaccesstokenrequests"), "", fmt.Errorf("only users can create personal access tokens"))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	// a user that was deleted and created again is a different user
+	if userInfo.GetUID() != string(user.UID) {
+		return nil, "", errors.NewForbidden(oauth.Resource("useroauthaccesstokenrequests"), "", fmt.Errorf("the request is not authenticated as the current user %q", user.Name))
+	}
+
+	scopes, err := personalAccessTokenScopes(requestedScopes, userInfo.GetExtra()[authorizationv1.ScopesKey])
+	if err != nil {
+		return nil, "", errors.NewForbidden(oauth.Resource("useroauthaccesstokenrequests"), "", err)
+	}
+
+	limits, err := r.sessionPolicy.LimitsFor(user)
+	if err != nil {
+		return nil, "", err
+	}
+	expiresIn := personalAccessTokenExpiresIn(requestedExpiresIn, sessionpolicy.Strictest(r.maxLifetime, limits.MaxAge))
+
+	ctx, err = r.withSessionOfRequest(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+	name, _ := tokenname.ObjectName(token)
+
+	created, err := r.accessTokenStorage.Create(oauthaccesstoken.WithPersonalAccessToken(ctx),
+		&oauthapi.OAuthAccessToken{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			ClientName:  oauthapi.PersonalAccessTokenClientName,
+			ExpiresIn:   expiresIn,
+			Scopes:      scopes,
+			RedirectURI: personalAccessTokenRedirectURI,
+			UserName:    user.Name,
+			UserUID:     string(user.UID),
+		},
+		rest.ValidateAllObjectFunc,
+		options,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	createdToken, ok := created.(*oauthapi.OAuthAccessToken)
+	if !ok {
+		return nil, "", errors.NewInternalError(fmt.Errorf("failed to convert generic accesstoken CREATE result to its typed version"))
+	}
+	return (*oauthapi.UserOAuthAccessToken)(createdToken), token, nil
+}
+
+// withSessionOfRequest continues the session of the OAuth access token the request is
+// authenticated with, if any. Requests authenticated otherwise start a new session.
+func (r *REST) withSessionOfRequest(ctx context.Context) (context.Context, error) {
+	current := currentTokenName(ctx)
+	if len(current) == 0 {
+		return ctx, nil
+	}
+	obj, err := r.accessTokenStorage.Get(ctx, current, &metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, errors.NewForbidden(oauth.Resource("useroauthaccesstokenrequests"), "", fmt.Errorf("the token of the request no longer exists"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sessionStart, ok := obj.(*oauthapi.OAuthAccessToken).Annotations[oauthapi.SessionStartAnnotation]; ok {
+		ctx = oauthaccesstoken.WithSessionStart(ctx, sessionStart)
+	}
+	return ctx, nil
+}
+
+// personalAccessTokenScopes returns the scopes of a new token, they default to the scopes of
+// the request. Requests without scopes are made with the full permissions of the user.
+func personalAccessTokenScopes(requested, callerScopes []string) ([]string, error) {
+	if len(requested) == 0 {
+		if len(callerScopes) == 0 {
+			return []string{userFullScope}, nil
+		}
+		return callerScopes, nil
+	}
+
+	if len(callerScopes) == 0 || sets.New(callerScopes...).Has(userFullScope) {
+		return requested, nil
+	}
+	if missing := sets.New(requested...).Difference(sets.New(callerScopes...)); missing.Len() > 0 {
+		return nil, fmt.Errorf("scopes %v are not granted to the token of the request", sets.List(missing))
+	}
+	return requested, nil
+}
+
+// personalAccessTokenExpiresIn caps the requested lifetime in seconds, 0 means the token
+// does not expire if the lifetime is not limited either.
+func personalAccessTokenExpiresIn(requested int64, maxLifetime time.Duration) int64 {
+	maxSeconds := int64(maxLifetime / time.Second)
+	if maxSeconds > 0 && (requested <= 0 || requested > maxSeconds) {
+		return maxSeconds
+	}
+	return requested
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return tokenname.SHA256Prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,235 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/storage"
+
+	"github.com/openshift/api/oauth"
+	userv1 "github.com/openshift/api/user/v1"
+	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+	accesstokenregistry "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken/etcd"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		requested    []string
+		callerScopes []string
+		want         []string
+		wantErr      bool
+	}{
+		{name: "unscoped caller gets full scopes", want: []string{"user:full"}},
+		{name: "scoped caller gets its scopes", callerScopes: []string{"user:info", "user:check-access"}, want: []string{"user:info", "user:check-access"}},
+		{name: "unscoped caller requests any scopes", requested: []string{"role:admin:myproject"}, want: []string{"role:admin:myproject"}},
+		{name: "full caller requests any scopes", requested: []string{"user:info"}, callerScopes: []string{"user:full"}, want: []string{"user:info"}},
+		{name: "subset of the caller scopes", requested: []string{"user:info"}, callerScopes: []string{"user:info", "user:check-access"}, want: []string{"user:info"}},
+		{name: "escalation", requested: []string{"user:info", "user:full"}, callerScopes: []string{"user:info"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := personalAccessTokenScopes(tc.requested, tc.callerScopes)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected scopes %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPersonalAccessTokenExpiresIn(t *testing.T) {
+	for _, tc := range []struct {
+		requested   int64
+		maxLifetime time.Duration
+		want        int64
+	}{
+		{requested: 0, maxLifetime: 0, want: 0},
+		{requested: 600, maxLifetime: 0, want: 600},
+		{requested: 0, maxLifetime: time.Hour, want: 3600},
+		{requested: 600, maxLifetime: time.Hour, want: 600},
+		{requested: 7200, maxLifetime: time.Hour, want: 3600},
+	} {
+		if got := personalAccessTokenExpiresIn(tc.requested, tc.maxLifetime); got != tc.want {
+			t.Errorf("requested %d with maximum %v: expected %d, got %d", tc.requested, tc.maxLifetime, tc.want, got)
+		}
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, err := generateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, tokenname.SHA256Prefix) {
+		t.Errorf("expected a %s token, got %q", tokenname.SHA256Prefix, token)
+	}
+	if _, ok := tokenname.ObjectName(token); !ok {
+		t.Errorf("expected token %q to map to an object name", token)
+	}
+	if other, _ := generateToken(); other == token {
+		t.Error("expected different tokens")
+	}
+}
+
+// memoryStorage keeps the tokens by key, deleting the keys in failDeletes fails
+type memoryStorage struct {
+	storage.Interface
+	tokens      map[string]*oauthapi.OAuthAccessToken
+	failDeletes sets.Set[string]
+}
+
+func (s *memoryStorage) Create(_ context.Context, key string, obj, out runtime.Object, _ uint64) error {
+	if _, ok := s.tokens[key]; ok {
+		return storage.NewKeyExistsError(key, 0)
+	}
+	s.tokens[key] = obj.(*oauthapi.OAuthAccessToken).DeepCopy()
+	s.tokens[key].DeepCopyInto(out.(*oauthapi.OAuthAccessToken))
+	return nil
+}
+
+func (s *memoryStorage) Get(_ context.Context, key string, _ storage.GetOptions, objPtr runtime.Object) error {
+	token, ok := s.tokens[key]
+	if !ok {
+		return storage.NewKeyNotFoundError(key, 0)
+	}
+	token.DeepCopyInto(objPtr.(*oauthapi.OAuthAccessToken))
+	return nil
+}
+
+func (s *memoryStorage) GetList(_ context.Context, _ string, opts storage.ListOptions, listObj runtime.Object) error {
+	list := listObj.(*oauthapi.OAuthAccessTokenList)
+	for _, key := range sets.List(sets.KeySet(s.tokens)) {
+		matches, err := opts.Predicate.Matches(s.tokens[key])
+		if err != nil {
+			return err
+		}
+		if matches {
+			list.Items = append(list.Items, *s.tokens[key].DeepCopy())
+		}
+	}
+	return nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string, out runtime.Object, _ *storage.Preconditions, _ storage.ValidateObjectFunc, _ runtime.Object, _ storage.DeleteOptions) error {
+	token, ok := s.tokens[key]
+	if !ok {
+		return storage.NewKeyNotFoundError(key, 0)
+	}
+	if s.failDeletes.Has(key) {
+		return storage.NewInternalError(fmt.Errorf("failed to delete %s", key))
+	}
+	delete(s.tokens, key)
+	token.DeepCopyInto(out.(*oauthapi.OAuthAccessToken))
+	return nil
+}
+
+func TestCreate(t *testing.T) {
+	sessionStart := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	current := &oauthapi.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: "sha256~current", Annotations: map[string]string{oauthapi.SessionStartAnnotation: sessionStart}},
+		ClientName: "console",
+		UserName:   "foo",
+		UserUID:    "bar",
+	}
+	withCredential := func(name string) map[string][]string {
+		return map[string][]string{kuser.CredentialIDKey: {tokenname.CredentialID(name)}}
+	}
+
+	for _, test := range []struct {
+		name                 string
+		user                 *kuser.DefaultInfo
+		expectedErr          func(error) bool
+		expectedSessionStart string
+	}{
+		{
+			name:                 "continues the session of the token of the request",
+			user:                 &kuser.DefaultInfo{Name: "foo", UID: "bar", Extra: withCredential(current.Name)},
+			expectedSessionStart: sessionStart,
+		},
+		{
+			name: "starts a new session without a token",
+			user: &kuser.DefaultInfo{Name: "foo", UID: "bar"},
+		},
+		{
+			name:        "token of the request was deleted",
+			user:        &kuser.DefaultInfo{Name: "foo", UID: "bar", Extra: withCredential("sha256~deleted")},
+			expectedErr: errors.IsForbidden,
+		},
+		{
+			name:        "user was created again",
+			user:        &kuser.DefaultInfo{Name: "foo", UID: "old", Extra: withCredential(current.Name)},
+			expectedErr: errors.IsForbidden,
+		},
+		{
+			name:        "no user object",
+			user:        &kuser.DefaultInfo{Name: "system:admin"},
+			expectedErr: errors.IsForbidden,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := &memoryStorage{tokens: map[string]*oauthapi.OAuthAccessToken{"/oauthaccesstokens/" + current.Name: current.DeepCopy()}}
+			r := &REST{
+				accessTokenStorage: &accesstokenregistry.REST{Store: &registry.Store{
+					NewFunc:                  func() runtime.Object { return &oauthapi.OAuthAccessToken{} },
+					DefaultQualifiedResource: oauth.Resource("oauthaccesstokens"),
+					KeyFunc:                  func(_ context.Context, name string) (string, error) { return "/oauthaccesstokens/" + name, nil },
+					ObjectNameFunc:           func(obj runtime.Object) (string, error) { return obj.(*oauthapi.OAuthAccessToken).Name, nil },
+					CreateStrategy:           oauthaccesstoken.NewStrategy(nil, nil, nil, oauthaccesstoken.LifetimeLimit{}),
+					Storage:                  registry.DryRunnableStorage{Storage: tokens},
+				}},
+				users: userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}}).UserV1().Users(),
+			}
+			ctx := apirequest.WithUser(apirequest.WithNamespace(context.TODO(), metav1.NamespaceNone), test.user)
+
+			created, token, err := r.CreateToken(ctx, nil, 600, &metav1.CreateOptions{})
+			if test.expectedErr != nil {
+				if !test.expectedErr(err) {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if len(tokens.tokens) != 1 {
+					t.Errorf("expected no token to be created, got %d tokens", len(tokens.tokens))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name, ok := tokenname.ObjectName(token); !ok || name != created.Name || name == token {
+				t.Fatalf("expected the token of %q, got %q", created.Name, token)
+			}
+
+			stored, ok := tokens.tokens["/oauthaccesstokens/"+created.Name]
+			if !ok {
+				t.Fatalf("expected the token to be stored by its hash %q", created.Name)
+			}
+			if stored.UserName != "foo" || stored.UserUID != "bar" || stored.ClientName != oauthapi.PersonalAccessTokenClientName {
+				t.Errorf("expected a personal access token of foo with UID bar, got user %q UID %q client %q", stored.UserName, stored.UserUID, stored.ClientName)
+			}
+			expectedSessionStart := test.expectedSessionStart
+			if len(expectedSessionStart) == 0 {
+				expectedSessionStart = stored.CreationTimestamp.UTC().Format(time.RFC3339)
+			}
+			if got := stored.Annotations[oauthapi.SessionStartAnnotation]; got != expectedSessionStart {
+				t.Errorf("expected the session to start at %s, got %s", expectedSessionStart, got)
+			}
+		})
+	}
+}
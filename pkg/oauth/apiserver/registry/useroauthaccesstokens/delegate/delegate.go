@@ -3,7 +3,7 @@ package delegate
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
@@ -11,41 +11,60 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 
 	"github.com/openshift/api/oauth"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	userclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 
 	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
 	accesstokenregistry "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken/etcd"
 	oauthprinters "github.com/openshift/oauth-apiserver/pkg/oauth/printers/internalversion"
 	"github.com/openshift/oauth-apiserver/pkg/printers"
 	"github.com/openshift/oauth-apiserver/pkg/printerstorage"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
 )
 
+// currentTokenAlias is the name of the token the request is authenticated with
+const currentTokenAlias = "~"
+
 // REST implements a RESTStorage for access tokens a user owns (based on the userName field)
 type REST struct {
 	accessTokenStorage *accesstokenregistry.REST
+	users              userclient.UserInterface
+	sessionPolicy      *tokenvalidation.SessionPolicyEvaluator
+	maxLifetime        time.Duration
 
 	tableConvertor rest.TableConvertor
 }
 
-// we only allow retrieving the tokens => no Create() or Update()
+// we allow retrieving the tokens, changing their labels and description and deleting them
+// one by one or all at once. New tokens are created through useroauthaccesstokenrequests,
+// see CreateToken.
 var (
+	_ rest.Updater              = &REST{}
 	_ rest.Lister               = &REST{}
 	_ rest.Getter               = &REST{}
 	_ rest.Watcher              = &REST{}
 	_ rest.GracefulDeleter      = &REST{}
+	_ rest.CollectionDeleter    = &REST{}
 	_ rest.Scoper               = &REST{}
 	_ rest.SingularNameProvider = &REST{}
 	_ rest.Storage              = &REST{}
 )
 
-// NewREST returns a RESTStorage object that will work against access tokens
-func NewREST(accessTokenStorage *accesstokenregistry.REST) (*REST, error) {
+// NewREST returns a RESTStorage object that will work against access tokens. The users
+// create tokens for themselves with CreateToken, valid for at most maxLifetime and the maximum age the
+// session policy sets for them. A maxLifetime of 0 does not limit the lifetime.
+func NewREST(accessTokenStorage *accesstokenregistry.REST, users userclient.UserInterface, sessionPolicy *tokenvalidation.SessionPolicyEvaluator, maxLifetime time.Duration) (*REST, error) {
 	return &REST{
 		accessTokenStorage: accessTokenStorage,
+		users:              users,
+		sessionPolicy:      sessionPolicy,
+		maxLifetime:        maxLifetime,
 		tableConvertor:     printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(oauthprinters.AddOAuthOpenShiftHandler)},
 	}, nil
 }
@@ -68,19 +87,41 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 		return &oauthapi.UserOAuthAccessTokenList{}, nil
 	}
 
-	sanitizedListOpts := listOptionsWithUserNameFilter(options, ctxUserName)
-
-	tokenListGenericObj, err := r.accessTokenStorage.List(ctx, sanitizedListOpts)
+	options, err := listOptionsWithCurrentToken(ctx, options)
 	if err != nil {
 		return nil, err
 	}
+	sanitizedListOpts := listOptionsWithUserNameFilter(options, ctxUserName)
+	limit := sanitizedListOpts.Limit
+
+	// the store fills the pages with the tokens of the user, but the tokens isValidUserToken
+	// rejects are only dropped here. Keep listing from where the store stopped until the page
+	// is full, so that only the last page is short.
+	ret := &oauthapi.UserOAuthAccessTokenList{}
+	for {
+		tokenListGenericObj, err := r.accessTokenStorage.List(ctx, sanitizedListOpts)
+		if err != nil {
+			return nil, err
+		}
 
-	tokenList, ok := tokenListGenericObj.(*oauthapi.OAuthAccessTokenList)
-	if !ok {
-		return nil, errors.NewInternalError(fmt.Errorf("failed to convert generic accesstoken LIST result to its typed version"))
-	}
+		tokenList, ok := tokenListGenericObj.(*oauthapi.OAuthAccessTokenList)
+		if !ok {
+			return nil, errors.NewInternalError(fmt.Errorf("failed to convert generic accesstoken LIST result to its typed version"))
+		}
 
-	return oauthAccessTokenListToUserOAuthAccessTokenList(tokenList, ctxUserName), nil
+		page := oauthAccessTokenListToUserOAuthAccessTokenList(tokenList, ctxUserName)
+		ret.Items = append(ret.Items, page.Items...)
+		ret.ListMeta = page.ListMeta
+		if limit <= 0 || len(page.Continue) == 0 || len(page.Items) == len(tokenList.Items) {
+			return ret, nil
+		}
+
+		// the continue token pins the resource version of the first page
+		sanitizedListOpts.Continue = page.Continue
+		sanitizedListOpts.ResourceVersion = ""
+		sanitizedListOpts.ResourceVersionMatch = ""
+		sanitizedListOpts.Limit = limit - int64(len(ret.Items))
+	}
 }
 
 func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
@@ -89,6 +130,11 @@ func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions)
 		return nil, errors.NewNotFound(oauth.Resource("useroauthaccesstokens"), name)
 	}
 
+	name, err := resolveTokenName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
 	tokenGenericObj, err := r.accessTokenStorage.Get(ctx, name, options)
 	if err != nil {
 		return nil, err
@@ -112,6 +158,10 @@ func (r *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (wa
 		return watch.NewEmptyWatch(), nil
 	}
 
+	options, err := listOptionsWithCurrentToken(ctx, options)
+	if err != nil {
+		return nil, err
+	}
 	sanitizedListOpts := listOptionsWithUserNameFilter(options, ctxUserName)
 
 	tokenListWatcher, err := r.accessTokenStorage.Watch(ctx, sanitizedListOpts)
@@ -142,6 +192,12 @@ func (r *REST) Delete(ctx context.Context, name string, validateFunc rest.Valida
 		return nil, false, errors.NewNotFound(oauth.Resource("useroauthaccesstokens"), name)
 	}
 
+	// deleting ~ logs the request out
+	name, err := resolveTokenName(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
 	var deletedRV string
 	if options.Preconditions != nil && options.Preconditions.ResourceVersion != nil {
 		deletedRV = *options.Preconditions.ResourceVersion
@@ -189,6 +245,32 @@ func getUserFromContext(ctx context.Context) (string, bool) {
 	return userName, true
 }
 
+// currentTokenName returns the name of the token the request is authenticated with, or ""
+func currentTokenName(ctx context.Context) string {
+	userInfo, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return ""
+	}
+	for _, credentialID := range userInfo.GetExtra()[kuser.CredentialIDKey] {
+		if name, ok := tokenname.ObjectNameFromCredentialID(credentialID); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// resolveTokenName returns the name of the token of the request for ~ and any other name as is
+func resolveTokenName(ctx context.Context, name string) (string, error) {
+	if name != currentTokenAlias {
+		return name, nil
+	}
+	current := currentTokenName(ctx)
+	if len(current) == 0 {
+		return "", errors.NewForbidden(oauth.Resource("useroauthaccesstokens"), currentTokenAlias, fmt.Errorf("requests to ~ must be authenticated with an OAuth access token"))
+	}
+	return current, nil
+}
+
 func oauthAccessTokenListToUserOAuthAccessTokenList(l *oauthapi.OAuthAccessTokenList, username string) *oauthapi.UserOAuthAccessTokenList {
 	ret := oauthapi.UserOAuthAccessTokenList{}
 	for _, t := range l.Items {
@@ -212,16 +294,16 @@ func listOptionsWithUserNameFilter(opts *metainternal.ListOptions, userName stri
 
 	userSelector := fields.Set{"userName": userName}.AsSelector()
 	if newOpts.FieldSelector != nil {
-		fields.AndSelectors(userSelector, newOpts.FieldSelector)
+		newOpts.FieldSelector = fields.AndSelectors(userSelector, newOpts.FieldSelector)
 	} else {
 		newOpts.FieldSelector = userSelector
 	}
 	return newOpts
 }
 
-// isValidUserToken returns true if the token has the sha256~ prefix and token.User matches
-// the username provided
+// isValidUserToken returns true if the token has the prefix of a supported hashing scheme
+// and token.User matches the username provided
 func isValidUserToken(token *oauthapi.OAuthAccessToken, username string) bool {
 	// don't reveal other people tokens' hashes or non-hashed tokens
-	return token.UserName == username && strings.HasPrefix(token.Name, "sha256~")
+	return token.UserName == username && tokenname.HasSupportedPrefix(token.Name)
 }
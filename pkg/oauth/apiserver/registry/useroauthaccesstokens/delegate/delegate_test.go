@@ -0,0 +1,270 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/storage"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	accesstokenregistry "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken/etcd"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+func TestResolveTokenName(t *testing.T) {
+	withToken := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{
+		Name:  "foo",
+		Extra: map[string][]string{kuser.CredentialIDKey: {tokenname.CredentialID("sha256~current")}},
+	})
+	withoutToken := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{Name: "foo"})
+
+	if name, err := resolveTokenName(withToken, "~"); err != nil || name != "sha256~current" {
+		t.Errorf("expected ~ to resolve to sha256~current, got %q, %v", name, err)
+	}
+	if name, err := resolveTokenName(withToken, "sha256~other"); err != nil || name != "sha256~other" {
+		t.Errorf("expected sha256~other to be kept, got %q, %v", name, err)
+	}
+	if _, err := resolveTokenName(withoutToken, "~"); !errors.IsForbidden(err) {
+		t.Errorf("expected ~ to be forbidden without a token, got %v", err)
+	}
+}
+
+// pagedStorage serves the tokens in order, continue tokens are the index of the next token.
+// Watches get the events of watcher.
+type pagedStorage struct {
+	storage.Interface
+	tokens []oauthapi.OAuthAccessToken
+
+	watcher   *watch.FakeWatcher
+	watchOpts storage.ListOptions
+}
+
+func (s *pagedStorage) Watch(_ context.Context, _ string, opts storage.ListOptions) (watch.Interface, error) {
+	s.watchOpts = opts
+	return s.watcher, nil
+}
+
+func (s *pagedStorage) GetList(ctx context.Context, key string, opts storage.ListOptions, listObj runtime.Object) error {
+	list := listObj.(*oauthapi.OAuthAccessTokenList)
+	start := 0
+	if len(opts.Predicate.Continue) > 0 {
+		if len(opts.ResourceVersion) > 0 {
+			return fmt.Errorf("specifying resource version is not allowed when using continue")
+		}
+		start, _ = strconv.Atoi(opts.Predicate.Continue)
+	}
+	for i := start; i < len(s.tokens); i++ {
+		if opts.Predicate.Limit > 0 && int64(len(list.Items)) == opts.Predicate.Limit {
+			list.Continue = strconv.Itoa(i)
+			return nil
+		}
+		matches, err := opts.Predicate.Matches(&s.tokens[i])
+		if err != nil {
+			return err
+		}
+		if matches {
+			list.Items = append(list.Items, s.tokens[i])
+		}
+	}
+	return nil
+}
+
+// newPagedREST returns a REST of the tokens of the pagedStorage
+func newPagedREST(tokens *pagedStorage) *REST {
+	attrs := storage.AttrFunc(storage.DefaultClusterScopedAttr).WithFieldMutation(oauthapi.OAuthAccessTokenFieldSelector)
+	return &REST{accessTokenStorage: &accesstokenregistry.REST{Store: &registry.Store{
+		NewListFunc: func() runtime.Object { return &oauthapi.OAuthAccessTokenList{} },
+		KeyRootFunc: func(ctx context.Context) string { return "/oauthaccesstokens" },
+		KeyFunc:     func(ctx context.Context, name string) (string, error) { return "", fmt.Errorf("no single keys") },
+		PredicateFunc: func(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+			return storage.SelectionPredicate{Label: label, Field: field, GetAttrs: attrs}
+		},
+		Storage: registry.DryRunnableStorage{Storage: tokens},
+	}}}
+}
+
+func TestListPagination(t *testing.T) {
+	token := func(name, userName, clientName string) oauthapi.OAuthAccessToken {
+		return oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name}, UserName: userName, ClientName: clientName}
+	}
+	r := newPagedREST(&pagedStorage{tokens: []oauthapi.OAuthAccessToken{
+		token("sha256~a", "foo", "console"),
+		token("legacy1", "foo", "console"),
+		token("legacy2", "foo", "console"),
+		token("sha256~b", "foo", "cli"),
+		token("sha256~c", "foo", "console"),
+		token("sha256~d", "bar", "console"),
+		token("sha256~e", "foo", "console"),
+	}})
+	ctx := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{Name: "foo"})
+
+	list := func(opts *metainternal.ListOptions) ([]string, string) {
+		t.Helper()
+		obj, err := r.List(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, token := range obj.(*oauthapi.UserOAuthAccessTokenList).Items {
+			names = append(names, token.Name)
+		}
+		return names, obj.(*oauthapi.UserOAuthAccessTokenList).Continue
+	}
+
+	names, continueToken := list(&metainternal.ListOptions{Limit: 2, ResourceVersion: "0"})
+	if expected := []string{"sha256~a", "sha256~b"}; !reflect.DeepEqual(names, expected) || len(continueToken) == 0 {
+		t.Fatalf("expected %v and a continue token, got %v, %q", expected, names, continueToken)
+	}
+	names, continueToken = list(&metainternal.ListOptions{Limit: 2, Continue: continueToken})
+	if expected := []string{"sha256~c", "sha256~e"}; !reflect.DeepEqual(names, expected) || len(continueToken) != 0 {
+		t.Errorf("expected %v and no continue token, got %v, %q", expected, names, continueToken)
+	}
+
+	names, _ = list(&metainternal.ListOptions{FieldSelector: fields.OneTermEqualSelector("clientName", "console")})
+	if expected := []string{"sha256~a", "sha256~c", "sha256~e"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestListLabelSelector(t *testing.T) {
+	token := func(name, userName string, tokenLabels map[string]string) oauthapi.OAuthAccessToken {
+		return oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: tokenLabels}, UserName: userName, ClientName: "console"}
+	}
+	r := newPagedREST(&pagedStorage{tokens: []oauthapi.OAuthAccessToken{
+		token("sha256~a", "foo", map[string]string{"purpose": "ci"}),
+		token("sha256~b", "foo", map[string]string{"purpose": "laptop"}),
+		token("sha256~c", "foo", nil),
+		token("sha256~d", "bar", map[string]string{"purpose": "ci"}),
+		token("sha256~e", "foo", map[string]string{"purpose": "ci", "team": "infra"}),
+	}})
+	ctx := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{Name: "foo"})
+
+	for selector, expected := range map[string][]string{
+		"purpose=ci":             {"sha256~a", "sha256~e"},
+		"purpose!=ci":            {"sha256~b", "sha256~c"},
+		"purpose=ci,team=infra":  {"sha256~e"},
+		"purpose in (ci,laptop)": {"sha256~a", "sha256~b", "sha256~e"},
+		"!purpose":               {"sha256~c"},
+	} {
+		labelSelector, err := labels.Parse(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj, err := r.List(ctx, &metainternal.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, token := range obj.(*oauthapi.UserOAuthAccessTokenList).Items {
+			names = append(names, token.Name)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("%s: expected %v, got %v", selector, expected, names)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	token := func(name, userName, resourceVersion string) *oauthapi.OAuthAccessToken {
+		return &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion}, UserName: userName, ClientName: "console"}
+	}
+	sendInitialEvents := true
+
+	for _, tc := range []struct {
+		name     string
+		options  *metainternal.ListOptions
+		events   []watch.Event
+		expected []watch.Event
+	}{
+		{
+			name:    "resumes from the resource version",
+			options: &metainternal.ListOptions{ResourceVersion: "5"},
+			events: []watch.Event{
+				{Type: watch.Added, Object: token("sha256~other", "bar", "6")},
+				{Type: watch.Modified, Object: token("sha256~mine", "foo", "7")},
+			},
+			expected: []watch.Event{
+				{Type: watch.Modified, Object: (*oauthapi.UserOAuthAccessToken)(token("sha256~mine", "foo", "7"))},
+			},
+		},
+		{
+			name: "sends the initial events",
+			options: &metainternal.ListOptions{
+				SendInitialEvents:    &sendInitialEvents,
+				ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan,
+				AllowWatchBookmarks:  true,
+			},
+			events: []watch.Event{
+				{Type: watch.Added, Object: token("sha256~mine", "foo", "3")},
+				{Type: watch.Added, Object: token("sha256~other", "bar", "4")},
+				{Type: watch.Bookmark, Object: &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+					ResourceVersion: "5",
+					Annotations:     map[string]string{metav1.InitialEventsAnnotationKey: "true"},
+				}}},
+				{Type: watch.Deleted, Object: token("sha256~mine", "foo", "6")},
+			},
+			expected: []watch.Event{
+				{Type: watch.Added, Object: (*oauthapi.UserOAuthAccessToken)(token("sha256~mine", "foo", "3"))},
+				{Type: watch.Bookmark, Object: &oauthapi.UserOAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+					ResourceVersion: "5",
+					Annotations:     map[string]string{metav1.InitialEventsAnnotationKey: "true"},
+				}}},
+				{Type: watch.Deleted, Object: (*oauthapi.UserOAuthAccessToken)(token("sha256~mine", "foo", "6"))},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := &pagedStorage{watcher: watch.NewFakeWithChanSize(len(tc.events), false)}
+			ctx, cancel := context.WithCancel(apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{Name: "foo"}))
+			defer cancel()
+
+			w, err := newPagedREST(tokens).Watch(ctx, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			if tokens.watchOpts.ResourceVersion != tc.options.ResourceVersion {
+				t.Errorf("expected the watch to start at resource version %q, got %q", tc.options.ResourceVersion, tokens.watchOpts.ResourceVersion)
+			}
+			if !reflect.DeepEqual(tokens.watchOpts.SendInitialEvents, tc.options.SendInitialEvents) {
+				t.Errorf("expected sendInitialEvents %v, got %v", tc.options.SendInitialEvents, tokens.watchOpts.SendInitialEvents)
+			}
+			if tokens.watchOpts.Predicate.AllowWatchBookmarks != tc.options.AllowWatchBookmarks {
+				t.Errorf("expected allowWatchBookmarks %v, got %v", tc.options.AllowWatchBookmarks, tokens.watchOpts.Predicate.AllowWatchBookmarks)
+			}
+			if userName, ok := tokens.watchOpts.Predicate.Field.RequiresExactMatch("userName"); !ok || userName != "foo" {
+				t.Errorf("expected the watch to be limited to the tokens of foo, got %v", tokens.watchOpts.Predicate.Field)
+			}
+
+			for _, event := range tc.events {
+				tokens.watcher.Action(event.Type, event.Object)
+			}
+			for _, expected := range tc.expected {
+				select {
+				case event := <-w.ResultChan():
+					if !reflect.DeepEqual(event, expected) {
+						t.Errorf("expected %v, got %v", expected, event)
+					}
+				case <-time.After(wait.ForeverTestTimeout):
+					t.Fatalf("expected %v, got no event", expected)
+				}
+			}
+		})
+	}
+}
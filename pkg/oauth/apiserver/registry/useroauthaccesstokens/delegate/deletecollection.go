@@ -0,0 +1,95 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// DeleteCollection deletes all the tokens of the user that match the list options. Each token
+// is deleted on its own through Delete, so it gets the same checks as a single delete. The
+// token of the request is kept with the currentToken!=true field selector. A token that fails
+// to be deleted does not stop the others from being deleted, the errors are returned together.
+func (r *REST) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternal.ListOptions) (runtime.Object, error) {
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+
+	listObj, err := r.List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	tokens, ok := listObj.(*oauthapi.UserOAuthAccessTokenList)
+	if !ok {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to convert useroauthaccesstoken LIST result to its typed version"))
+	}
+
+	deleted := &oauthapi.UserOAuthAccessTokenList{ListMeta: tokens.ListMeta}
+	var errs []error
+	for _, token := range tokens.Items {
+		if _, _, err := r.Delete(ctx, token.Name, deleteValidation, options); err != nil {
+			// deleted in the meantime
+			if errors.IsNotFound(err) {
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		deleted.Items = append(deleted.Items, token)
+	}
+	// a single error keeps its status
+	if err := utilerrors.Reduce(utilerrors.NewAggregate(errs)); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// listOptionsWithCurrentToken replaces the currentToken requirements of the field selector with
+// requirements on the name of the token the request is authenticated with. Requests that are not
+// authenticated with a token have no current token.
+func listOptionsWithCurrentToken(ctx context.Context, opts *metainternal.ListOptions) (*metainternal.ListOptions, error) {
+	if opts == nil || opts.FieldSelector == nil {
+		return opts, nil
+	}
+	requirements := opts.FieldSelector.Requirements()
+	if !slices.ContainsFunc(requirements, func(r fields.Requirement) bool { return r.Field == oauthapi.UserOAuthAccessTokenCurrentTokenField }) {
+		return opts, nil
+	}
+
+	currentToken := currentTokenName(ctx)
+	selectors := make([]fields.Selector, 0, len(requirements))
+	for _, requirement := range requirements {
+		field, value, equals := requirement.Field, requirement.Value, requirement.Operator != selection.NotEquals
+		if field == oauthapi.UserOAuthAccessTokenCurrentTokenField {
+			switch value {
+			case "true":
+			case "false":
+				equals = !equals
+			default:
+				return nil, errors.NewBadRequest(fmt.Sprintf("%s must be true or false, got %q", field, value))
+			}
+			field, value = "metadata.name", currentToken
+		}
+
+		if equals {
+			selectors = append(selectors, fields.OneTermEqualSelector(field, value))
+		} else {
+			selectors = append(selectors, fields.OneTermNotEqualSelector(field, value))
+		}
+	}
+
+	newOpts := opts.DeepCopy()
+	newOpts.FieldSelector = fields.AndSelectors(selectors...)
+	return newOpts, nil
+}
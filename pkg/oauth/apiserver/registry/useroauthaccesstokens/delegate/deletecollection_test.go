@@ -0,0 +1,155 @@
+package delegate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+
+	"github.com/openshift/api/oauth"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+	accesstokenregistry "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken/etcd"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+func TestListOptionsWithCurrentToken(t *testing.T) {
+	withToken := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{
+		Name:  "foo",
+		Extra: map[string][]string{kuser.CredentialIDKey: {tokenname.CredentialID("sha256~current")}},
+	})
+	withCert := apirequest.WithUser(context.TODO(), &kuser.DefaultInfo{
+		Name:  "foo",
+		Extra: map[string][]string{kuser.CredentialIDKey: {"X509SHA256=abc"}},
+	})
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		selector string
+		expected string
+	}{
+		{name: "no selector", ctx: withToken},
+		{name: "other fields", ctx: withToken, selector: "clientName=x", expected: "clientName=x"},
+		{name: "current token", ctx: withToken, selector: "currentToken=true", expected: "metadata.name=sha256~current"},
+		{name: "keep current token", ctx: withToken, selector: "clientName=x,currentToken!=true", expected: "clientName=x,metadata.name!=sha256~current"},
+		{name: "not the current token", ctx: withToken, selector: "currentToken=false", expected: "metadata.name!=sha256~current"},
+		{name: "no current token", ctx: withCert, selector: "currentToken=true", expected: "metadata.name="},
+		{name: "keep no current token", ctx: withCert, selector: "currentToken!=true", expected: "metadata.name!="},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &metainternal.ListOptions{}
+			if len(tc.selector) > 0 {
+				opts.FieldSelector = fields.ParseSelectorOrDie(tc.selector)
+			}
+			got, err := listOptionsWithCurrentToken(tc.ctx, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.FieldSelector == nil {
+				if len(tc.expected) > 0 {
+					t.Fatalf("expected %q, got no selector", tc.expected)
+				}
+				return
+			}
+			if got.FieldSelector.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got.FieldSelector.String())
+			}
+		})
+	}
+
+	_, err := listOptionsWithCurrentToken(withToken, &metainternal.ListOptions{FieldSelector: fields.ParseSelectorOrDie("currentToken=yes")})
+	if !errors.IsBadRequest(err) {
+		t.Errorf("expected a bad request, got %v", err)
+	}
+}
+
+func TestDeleteCollection(t *testing.T) {
+	token := func(name, userName string) *oauthapi.OAuthAccessToken {
+		return &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name}, UserName: userName, ClientName: "console"}
+	}
+	attrs := storage.AttrFunc(storage.DefaultClusterScopedAttr).WithFieldMutation(oauthapi.OAuthAccessTokenFieldSelector)
+	newREST := func(tokens *memoryStorage) *REST {
+		return &REST{accessTokenStorage: &accesstokenregistry.REST{Store: &registry.Store{
+			NewFunc:                  func() runtime.Object { return &oauthapi.OAuthAccessToken{} },
+			NewListFunc:              func() runtime.Object { return &oauthapi.OAuthAccessTokenList{} },
+			DefaultQualifiedResource: oauth.Resource("oauthaccesstokens"),
+			KeyRootFunc:              func(_ context.Context) string { return "/oauthaccesstokens" },
+			KeyFunc:                  func(_ context.Context, name string) (string, error) { return "/oauthaccesstokens/" + name, nil },
+			ObjectNameFunc:           func(obj runtime.Object) (string, error) { return obj.(*oauthapi.OAuthAccessToken).Name, nil },
+			PredicateFunc: func(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+				return storage.SelectionPredicate{Label: label, Field: field, GetAttrs: attrs}
+			},
+			DeleteStrategy: oauthaccesstoken.NewStrategy(nil, nil, nil, oauthaccesstoken.LifetimeLimit{}),
+			Storage:        registry.DryRunnableStorage{Storage: tokens},
+		}}}
+	}
+	ctx := apirequest.WithUser(apirequest.WithNamespace(context.TODO(), metav1.NamespaceNone), &kuser.DefaultInfo{Name: "foo"})
+
+	for _, tc := range []struct {
+		name        string
+		failDeletes []string
+		expectedErr func(error) bool
+		remaining   []string
+	}{
+		{
+			name:      "deletes the tokens of the user",
+			remaining: []string{"sha256~d"},
+		},
+		{
+			name:        "keeps deleting after a failure",
+			failDeletes: []string{"sha256~a"},
+			expectedErr: errors.IsInternalError,
+			remaining:   []string{"sha256~a", "sha256~d"},
+		},
+		{
+			name:        "returns all the failures",
+			failDeletes: []string{"sha256~a", "sha256~c"},
+			expectedErr: func(err error) bool {
+				return err != nil && strings.Contains(err.Error(), "sha256~a") && strings.Contains(err.Error(), "sha256~c")
+			},
+			remaining: []string{"sha256~a", "sha256~c", "sha256~d"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := &memoryStorage{tokens: map[string]*oauthapi.OAuthAccessToken{}, failDeletes: sets.New[string]()}
+			for _, token := range []*oauthapi.OAuthAccessToken{token("sha256~a", "foo"), token("sha256~b", "foo"), token("sha256~c", "foo"), token("sha256~d", "bar")} {
+				tokens.tokens["/oauthaccesstokens/"+token.Name] = token
+			}
+			for _, name := range tc.failDeletes {
+				tokens.failDeletes.Insert("/oauthaccesstokens/" + name)
+			}
+
+			obj, err := newREST(tokens).DeleteCollection(ctx, rest.ValidateAllObjectFunc, nil, &metainternal.ListOptions{})
+			switch {
+			case tc.expectedErr == nil && err != nil:
+				t.Fatal(err)
+			case tc.expectedErr == nil && len(obj.(*oauthapi.UserOAuthAccessTokenList).Items) != 3:
+				t.Errorf("expected 3 deleted tokens, got %d", len(obj.(*oauthapi.UserOAuthAccessTokenList).Items))
+			case tc.expectedErr != nil && !tc.expectedErr(err):
+				t.Errorf("unexpected error %v", err)
+			}
+
+			remaining := []string{}
+			for _, token := range tokens.tokens {
+				remaining = append(remaining, token.Name)
+			}
+			if !sets.New(remaining...).Equal(sets.New(tc.remaining...)) {
+				t.Errorf("expected %v to remain, got %v", tc.remaining, remaining)
+			}
+		})
+	}
+}
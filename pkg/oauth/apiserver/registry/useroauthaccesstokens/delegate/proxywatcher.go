@@ -68,10 +68,16 @@ func (w *OAuthAccessTokenWatcher) Watch(ctx context.Context) {
 		select {
 		case <-w.stopCh:
 			return
-		case event := <-w.incoming:
+		case event, ok := <-w.incoming:
+			if !ok {
+				// the wrapped watch ended, the client resumes from the last resource version it got
+				return
+			}
 			switch event.Type {
 			case watch.Error:
-				w.outgoing <- event
+				if !w.send(ctx, event) {
+					return
+				}
 			default:
 				obj := event.Object
 				if cacheable, ok := obj.(runtime.CacheableObject); ok {
@@ -79,15 +85,21 @@ func (w *OAuthAccessTokenWatcher) Watch(ctx context.Context) {
 				}
 				tokenOrig, ok := obj.(*oauthapi.OAuthAccessToken)
 				if !ok {
-					w.outgoing <- createErrorEvent(errors.NewInternalError(fmt.Errorf("failed to convert incoming object to an OAuthAccessToken type")))
+					if !w.send(ctx, createErrorEvent(errors.NewInternalError(fmt.Errorf("failed to convert incoming object to an OAuthAccessToken type")))) {
+						return
+					}
 					continue
 				}
 
-				if !isValidUserToken(tokenOrig, w.username) {
+				// bookmarks carry no token, only the resource version and the end of the
+				// initial events of streaming lists in their annotations
+				if event.Type != watch.Bookmark && !isValidUserToken(tokenOrig, w.username) {
 					continue
 				}
 				event.Object = (*oauthapi.UserOAuthAccessToken)(tokenOrig)
-				w.outgoing <- event
+				if !w.send(ctx, event) {
+					return
+				}
 			}
 		case <-ctx.Done(): // user cancel
 			w.Stop()
@@ -96,6 +108,20 @@ func (w *OAuthAccessTokenWatcher) Watch(ctx context.Context) {
 	}
 }
 
+// send passes the event on to the client. It is false if the watch stopped before the
+// client received the event.
+func (w *OAuthAccessTokenWatcher) send(ctx context.Context, event watch.Event) bool {
+	select {
+	case w.outgoing <- event:
+		return true
+	case <-w.stopCh:
+		return false
+	case <-ctx.Done():
+		w.Stop()
+		return false
+	}
+}
+
 func createErrorEvent(err errors.APIStatus) watch.Event {
 	status := err.Status()
 	return watch.Event{
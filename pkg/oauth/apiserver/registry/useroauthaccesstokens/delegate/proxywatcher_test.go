@@ -0,0 +1,82 @@
+package delegate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+func TestOAuthAccessTokenWatcher(t *testing.T) {
+	wrapped := watch.NewFake()
+	watcher := newOAuthAccessTokenWatcher(wrapped, "foo")
+	go watcher.Watch(context.TODO())
+
+	go func() {
+		wrapped.Add(&oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~other", ResourceVersion: "1"}, UserName: "bar"})
+		wrapped.Add(&oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "legacy", ResourceVersion: "2"}, UserName: "foo"})
+		wrapped.Add(&oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~mine", ResourceVersion: "3"}, UserName: "foo"})
+		wrapped.Action(watch.Bookmark, &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+			ResourceVersion: "4",
+			Annotations:     map[string]string{metav1.InitialEventsAnnotationKey: "true"},
+		}})
+		wrapped.Stop()
+	}()
+
+	var events []watch.Event
+	timeout := time.After(wait.ForeverTestTimeout)
+	for done := false; !done; {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				done = true
+				break
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("the watch did not end after the wrapped watch stopped, got %v", events)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected the token of the user and the bookmark, got %v", events)
+	}
+	if token, ok := events[0].Object.(*oauthapi.UserOAuthAccessToken); events[0].Type != watch.Added || !ok || token.Name != "sha256~mine" {
+		t.Errorf("expected sha256~mine to be added, got %v", events[0])
+	}
+	bookmark, ok := events[1].Object.(*oauthapi.UserOAuthAccessToken)
+	if events[1].Type != watch.Bookmark || !ok || bookmark.ResourceVersion != "4" || bookmark.Annotations[metav1.InitialEventsAnnotationKey] != "true" {
+		t.Errorf("expected the bookmark at the end of the initial events, got %v", events[1])
+	}
+}
+
+func TestOAuthAccessTokenWatcherStop(t *testing.T) {
+	wrapped := watch.NewFakeWithChanSize(1, false)
+	watcher := newOAuthAccessTokenWatcher(wrapped, "foo")
+	done := make(chan struct{})
+	go func() {
+		watcher.Watch(context.TODO())
+		close(done)
+	}()
+
+	// nobody reads the event, stopping the watch must not block on it
+	wrapped.Add(&oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~mine"}, UserName: "foo"})
+	// the watcher took the event and waits for it to be read
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		return len(wrapped.ResultChan()) == 0, nil
+	}); err != nil {
+		t.Fatal("the watcher did not take the event")
+	}
+	watcher.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("the watch did not end after it was stopped")
+	}
+}
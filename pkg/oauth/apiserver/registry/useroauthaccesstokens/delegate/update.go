@@ -0,0 +1,97 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/openshift/api/oauth"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+// Update changes the labels and the description of a token the user owns. Changes to
+// any other field of the token are ignored.
+func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	ctxUserName, ok := getUserFromContext(ctx)
+	if !ok {
+		return nil, false, errors.NewNotFound(oauth.Resource("useroauthaccesstokens"), name)
+	}
+
+	updated, _, err := r.accessTokenStorage.Update(
+		ctx,
+		name,
+		&userTokenUpdate{name: name, userName: ctxUserName, objInfo: objInfo},
+		// tokens are only created through Create
+		rest.ValidateAllObjectFunc,
+		func(ctx context.Context, obj, old runtime.Object) error {
+			if updateValidation == nil {
+				return nil
+			}
+			return updateValidation(ctx, (*oauthapi.UserOAuthAccessToken)(obj.(*oauthapi.OAuthAccessToken)), (*oauthapi.UserOAuthAccessToken)(old.(*oauthapi.OAuthAccessToken)))
+		},
+		false,
+		options,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updatedToken, ok := updated.(*oauthapi.OAuthAccessToken)
+	if !ok {
+		return nil, false, errors.NewInternalError(fmt.Errorf("failed to convert generic accesstoken UPDATE result to its typed version"))
+	}
+	return (*oauthapi.UserOAuthAccessToken)(updatedToken), false, nil
+}
+
+// userTokenUpdate applies the labels and the description of the updated UserOAuthAccessToken
+// to the stored OAuthAccessToken, provided the user owns it
+type userTokenUpdate struct {
+	name     string
+	userName string
+	objInfo  rest.UpdatedObjectInfo
+}
+
+var _ rest.UpdatedObjectInfo = &userTokenUpdate{}
+
+func (u *userTokenUpdate) Preconditions() *metav1.Preconditions {
+	return u.objInfo.Preconditions()
+}
+
+func (u *userTokenUpdate) UpdatedObject(ctx context.Context, oldObj runtime.Object) (runtime.Object, error) {
+	oldToken, ok := oldObj.(*oauthapi.OAuthAccessToken)
+	if !ok {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to convert generic accesstoken to its typed version"))
+	}
+	// tokens of others do not exist for the user
+	if !isValidUserToken(oldToken, u.userName) {
+		return nil, errors.NewNotFound(oauth.Resource("useroauthaccesstokens"), u.name)
+	}
+
+	obj, err := u.objInfo.UpdatedObject(ctx, (*oauthapi.UserOAuthAccessToken)(oldToken.DeepCopy()))
+	if err != nil {
+		return nil, err
+	}
+	requested, ok := obj.(*oauthapi.UserOAuthAccessToken)
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("not a UserOAuthAccessToken: %#v", obj))
+	}
+
+	updated := oldToken.DeepCopy()
+	updated.ResourceVersion = requested.ResourceVersion
+	updated.ManagedFields = requested.ManagedFields
+	updated.Labels = requested.Labels
+	if description, ok := requested.Annotations[oauthapi.DescriptionAnnotation]; ok {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[oauthapi.DescriptionAnnotation] = description
+	} else {
+		delete(updated.Annotations, oauthapi.DescriptionAnnotation)
+	}
+	return updated, nil
+}
@@ -0,0 +1,72 @@
+package delegate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+)
+
+func TestUserTokenUpdate(t *testing.T) {
+	stored := &oauthapi.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sha256~token",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"purpose": "test"},
+			Annotations: map[string]string{
+				oauthapi.DescriptionAnnotation: "old",
+				oauthapi.LastUsedAnnotation:    "2020-01-01T00:00:00Z",
+			},
+		},
+		UserName: "foo",
+		Scopes:   []string{"user:info"},
+	}
+
+	requested := (*oauthapi.UserOAuthAccessToken)(stored.DeepCopy())
+	requested.Labels = map[string]string{"purpose": "ci"}
+	requested.Annotations = map[string]string{oauthapi.DescriptionAnnotation: "CI token"}
+	requested.Scopes = []string{"user:full"}
+	requested.InactivityTimeoutSeconds = 3600
+
+	update := &userTokenUpdate{name: stored.Name, userName: "foo", objInfo: rest.DefaultUpdatedObjectInfo(requested)}
+	obj, err := update.UpdatedObject(context.TODO(), stored.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := stored.DeepCopy()
+	expected.Labels = map[string]string{"purpose": "ci"}
+	expected.Annotations[oauthapi.DescriptionAnnotation] = "CI token"
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected only the labels and the description to change, got %#v", obj)
+	}
+
+	requested.Annotations = nil
+	obj, err = update.UpdatedObject(context.TODO(), stored.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := obj.(*oauthapi.OAuthAccessToken).Annotations[oauthapi.DescriptionAnnotation]; ok {
+		t.Error("expected the description to be removed")
+	}
+
+	other := &userTokenUpdate{name: stored.Name, userName: "bar", objInfo: rest.DefaultUpdatedObjectInfo(requested)}
+	if _, err := other.UpdatedObject(context.TODO(), stored.DeepCopy()); !errors.IsNotFound(err) {
+		t.Errorf("expected tokens of other users not to be found, got %v", err)
+	}
+}
+
+func TestListOptionsWithUserNameFilter(t *testing.T) {
+	opts := listOptionsWithUserNameFilter(&metainternal.ListOptions{FieldSelector: fields.OneTermEqualSelector("clientName", "myclient")}, "foo")
+	for field, value := range map[string]string{"userName": "foo", "clientName": "myclient"} {
+		if got, ok := opts.FieldSelector.RequiresExactMatch(field); !ok || got != value {
+			t.Errorf("expected the field selector %q to require %s=%s", opts.FieldSelector, field, value)
+		}
+	}
+}
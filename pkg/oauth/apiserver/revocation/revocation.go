@@ -0,0 +1,149 @@
+package revocation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+
+	oauthlisters "github.com/openshift/client-go/oauth/listers/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/clientauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+// Path serves the OAuth 2.0 token revocation (RFC 7009) of OAuth access tokens.
+const Path = "/openshift/oauth/v1/revoke"
+
+// RevokedTokensAnnotation is the audit annotation with the number of access tokens
+// deleted by a revocation request.
+const RevokedTokensAnnotation = "oauth.openshift.io/revoked-access-tokens"
+
+// revokeSessionParameter is an extension parameter. If "true", all the access tokens
+// issued from the same authorize token as the revoked token are revoked as well.
+const revokeSessionParameter = "revoke_session"
+
+// maxRequestBytes is plenty for a token and client credentials
+const maxRequestBytes = 64 * 1024
+
+// AccessTokenStorage is the storage of the access tokens, usually the oauthaccesstokens REST.
+type AccessTokenStorage interface {
+	rest.Getter
+	rest.Lister
+	rest.GracefulDeleter
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the revocation handler. Callers authenticate as an OAuth client and
+// may only revoke the tokens issued to that client. The client credentials are kept for
+// the handler by clientauth.WithClientCredentials. The tokens of a session are listed from
+// the storage with an authorizeToken field selector.
+func NewHandler(accessTokens AccessTokenStorage, clients oauthlisters.OAuthClientLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		req.Body = http.MaxBytesReader(w, req.Body, maxRequestBytes)
+		if err := req.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		client, ok := clientauth.AuthenticateClient(req, clients)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="revocation"`)
+			writeError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		// token_type_hint is ignored, access tokens are the only tokens that can be revoked
+		token := req.PostForm.Get("token")
+		if len(token) == 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		name, ok := tokenname.ObjectName(token)
+		if !ok {
+			// invalid tokens do not cause an error, see RFC 7009 section 2.2
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		ctx := req.Context()
+		obj, err := accessTokens.Get(ctx, name, &metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err != nil {
+			klog.Errorf("failed to get the access token to revoke: %v", err)
+			writeError(w, http.StatusServiceUnavailable, "temporarily_unavailable")
+			return
+		}
+		accessToken := obj.(*oauthapi.OAuthAccessToken)
+		if accessToken.ClientName != client.Name {
+			// tokens of other clients are treated like unknown tokens, so that the response
+			// does not tell whether they exist, see RFC 7009 section 2.2
+			klog.V(4).Infof("OAuth client %q tried to revoke an access token of client %q", client.Name, accessToken.ClientName)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		names := []string{name}
+		if req.PostForm.Get(revokeSessionParameter) == "true" && len(accessToken.AuthorizeToken) > 0 {
+			obj, err := accessTokens.List(ctx, &metainternalversion.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("authorizeToken", accessToken.AuthorizeToken),
+			})
+			if err != nil {
+				klog.Errorf("failed to list the access tokens of the session to revoke: %v", err)
+				writeError(w, http.StatusServiceUnavailable, "temporarily_unavailable")
+				return
+			}
+			for _, sessionToken := range obj.(*oauthapi.OAuthAccessTokenList).Items {
+				if sessionToken.Name != name && sessionToken.ClientName == client.Name {
+					names = append(names, sessionToken.Name)
+				}
+			}
+		}
+
+		revoked := 0
+		for _, name := range names {
+			_, _, err := accessTokens.Delete(ctx, name, rest.ValidateAllObjectFunc, &metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				klog.Errorf("failed to revoke an access token of client %q: %v", client.Name, err)
+				writeError(w, http.StatusServiceUnavailable, "temporarily_unavailable")
+				return
+			}
+			if err == nil {
+				revoked++
+			}
+		}
+
+		audit.AddAuditAnnotation(ctx, RevokedTokensAnnotation, strconv.Itoa(revoked))
+		klog.V(2).Infof("OAuth client %q revoked %d access tokens", client.Name, revoked)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, oauthError string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorResponse{Error: oauthError}); err != nil {
+		klog.Errorf("failed to write revocation response: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package revocation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/tools/cache"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthlisters "github.com/openshift/client-go/oauth/listers/oauth/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/clientauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+func objectName(t *testing.T, token string) string {
+	name, ok := tokenname.ObjectName(token)
+	if !ok {
+		t.Fatalf("unsupported token %q", token)
+	}
+	return name
+}
+
+type fakeStorage struct {
+	rest.TableConvertor
+	tokens  map[string]*oauthapi.OAuthAccessToken
+	listErr error
+	deleted []string
+}
+
+func (f *fakeStorage) New() runtime.Object     { return &oauthapi.OAuthAccessToken{} }
+func (f *fakeStorage) NewList() runtime.Object { return &oauthapi.OAuthAccessTokenList{} }
+func (f *fakeStorage) Destroy()                {}
+
+func (f *fakeStorage) Get(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	token, ok := f.tokens[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(oauthapi.Resource("oauthaccesstokens"), name)
+	}
+	return token, nil
+}
+
+func (f *fakeStorage) List(_ context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	list := &oauthapi.OAuthAccessTokenList{}
+	for _, token := range f.tokens {
+		set := fields.Set{}
+		if err := oauthapi.OAuthAccessTokenFieldSelector(token, set); err != nil {
+			return nil, err
+		}
+		if options.FieldSelector.Matches(set) {
+			list.Items = append(list.Items, *token)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeStorage) Delete(_ context.Context, name string, _ rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	token, ok := f.tokens[name]
+	if !ok {
+		return nil, false, apierrors.NewNotFound(oauthapi.Resource("oauthaccesstokens"), name)
+	}
+	delete(f.tokens, name)
+	f.deleted = append(f.deleted, name)
+	return token, true, nil
+}
+
+func TestRevocation(t *testing.T) {
+	clients := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := clients.Add(&oauthv1.OAuthClient{ObjectMeta: metav1.ObjectMeta{Name: "console"}, Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name            string
+		basicAuth       []string
+		form            url.Values
+		listErr         error
+		expectedStatus  int
+		expectedError   string
+		expectedDeleted []string
+	}{
+		{
+			name:            "own token",
+			basicAuth:       []string{"console", "secret"},
+			form:            url.Values{"token": {"sha256~mine"}},
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: []string{"sha256~mine"},
+		},
+		{
+			name:            "own token and its session",
+			basicAuth:       []string{"console", "secret"},
+			form:            url.Values{"token": {"sha256~mine"}, "revoke_session": {"true"}},
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: []string{"sha256~mine", "sha256~mine-refreshed"},
+		},
+		{
+			name:           "own token and its session when the session cannot be listed",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{"token": {"sha256~mine"}, "revoke_session": {"true"}},
+			listErr:        apierrors.NewServiceUnavailable("etcd is unavailable"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  "temporarily_unavailable",
+		},
+		{
+			name:           "token of another client",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{"token": {"sha256~theirs"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token of another client and its session",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{"token": {"sha256~same-code"}, "revoke_session": {"true"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown token",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{"token": {"sha256~unknown"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token without a supported prefix",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{"token": {"mine"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			basicAuth:      []string{"console", "secret"},
+			form:           url.Values{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "wrong secret",
+			basicAuth:      []string{"console", "wrong"},
+			form:           url.Values{"token": {"sha256~mine"}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid_client",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			storage := &fakeStorage{tokens: map[string]*oauthapi.OAuthAccessToken{}, listErr: test.listErr}
+			for token, clientName := range map[string]string{
+				"sha256~mine":           "console",
+				"sha256~mine-refreshed": "console",
+				"sha256~same-code":      "other",
+				"sha256~theirs":         "other",
+			} {
+				accessToken := &oauthapi.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: objectName(t, token)}, ClientName: clientName}
+				if token != "sha256~theirs" {
+					accessToken.AuthorizeToken = "sha256~code"
+				}
+				storage.tokens[accessToken.Name] = accessToken
+			}
+			handler := clientauth.WithClientCredentials(NewHandler(storage, oauthlisters.NewOAuthClientLister(clients)), Path)
+
+			req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.basicAuth != nil {
+				req.SetBasicAuth(test.basicAuth[0], test.basicAuth[1])
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if len(test.expectedError) > 0 && !strings.Contains(w.Body.String(), `"error":"`+test.expectedError+`"`) {
+				t.Errorf("expected error %q, got %s", test.expectedError, w.Body.String())
+			}
+			var expectedDeleted []string
+			for _, token := range test.expectedDeleted {
+				expectedDeleted = append(expectedDeleted, objectName(t, token))
+			}
+			sort.Strings(storage.deleted)
+			sort.Strings(expectedDeleted)
+			if !reflect.DeepEqual(storage.deleted, expectedDeleted) {
+				t.Errorf("expected deleted tokens %v, got %v", expectedDeleted, storage.deleted)
+			}
+		})
+	}
+}
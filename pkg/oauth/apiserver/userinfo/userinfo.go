@@ -0,0 +1,141 @@
+package userinfo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/klog/v2"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	userlisters "github.com/openshift/client-go/user/listers/user/v1"
+	"github.com/openshift/library-go/pkg/authorization/scopemetadata"
+)
+
+// Path serves the OIDC userinfo of the owner of an OAuth access token.
+const Path = "/openshift/oauth/v1/userinfo"
+
+// userFullScope is the scope that grants all the permissions of the user
+const userFullScope = "user:full"
+
+type bearerTokenKey struct{}
+
+// claims are the standard OIDC claims, see https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+type claims struct {
+	Subject           string   `json:"sub"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name,omitempty"`
+	Groups            []string `json:"groups"`
+	Scopes            []string `json:"scopes"`
+}
+
+// WithBearerToken keeps the bearer token of userinfo requests in the request context.
+// It has to wrap the authentication filter, which removes the Authorization header.
+func WithBearerToken(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == Path {
+			if token, ok := bearerToken(req); ok {
+				req = req.WithContext(context.WithValue(req.Context(), bearerTokenKey{}, token))
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// NewHandler returns the userinfo handler. The token of the request is validated with
+// tokenAuthenticator and must grant access to the user info.
+func NewHandler(tokenAuthenticator kauthenticator.Token, users userlisters.UserLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := req.Context().Value(bearerTokenKey{}).(string)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		resp, ok, err := tokenAuthenticator.AuthenticateToken(req.Context(), token)
+		if err != nil || !ok {
+			if err != nil {
+				klog.V(4).Infof("userinfo token authentication failed: %v", err)
+			}
+			writeBearerError(w, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+
+		scopes := resp.User.GetExtra()[authorizationv1.ScopesKey]
+		if !grantsUserInfo(scopes) {
+			writeBearerError(w, http.StatusForbidden, "insufficient_scope")
+			return
+		}
+
+		userInfo := claims{
+			Subject:           resp.User.GetUID(),
+			PreferredUsername: resp.User.GetName(),
+			Groups:            resp.User.GetGroups(),
+			Scopes:            scopes,
+		}
+		if userInfo.Groups == nil {
+			userInfo.Groups = []string{}
+		}
+		if userInfo.Scopes == nil {
+			userInfo.Scopes = []string{}
+		}
+		user, err := users.Get(resp.User.GetName())
+		switch {
+		case err == nil:
+			userInfo.Name = user.FullName
+		case !apierrors.IsNotFound(err):
+			klog.Errorf("failed to get user %q for userinfo: %v", resp.User.GetName(), err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(userInfo.Subject) == 0 {
+			// users without an object, like the bootstrap user, have no UID
+			userInfo.Subject = userInfo.PreferredUsername
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(userInfo); err != nil {
+			klog.Errorf("failed to write userinfo: %v", err)
+		}
+	})
+}
+
+// grantsUserInfo is true if a token with the scopes may read the user's info.
+// Tokens without any scopes are not restricted.
+func grantsUserInfo(scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == scopemetadata.UserInfo || scope == userFullScope {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	auth := strings.TrimSpace(req.Header.Get("Authorization"))
+	scheme, token, found := strings.Cut(auth, " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, len(token) > 0
+}
+
+func writeBearerError(w http.ResponseWriter, status int, bearerError string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="`+bearerError+`"`)
+	w.WriteHeader(status)
+}
@@ -0,0 +1,119 @@
+package userinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/cache"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	userlisters "github.com/openshift/client-go/user/listers/user/v1"
+)
+
+func TestUserInfo(t *testing.T) {
+	tokens := map[string]*kuser.DefaultInfo{
+		"full":    {Name: "foo", UID: "bar", Groups: []string{"devs"}, Extra: map[string][]string{authorizationv1.ScopesKey: {"user:full"}}},
+		"info":    {Name: "foo", UID: "bar", Extra: map[string][]string{authorizationv1.ScopesKey: {"user:info"}}},
+		"check":   {Name: "foo", UID: "bar", Extra: map[string][]string{authorizationv1.ScopesKey: {"user:check-access"}}},
+		"noscope": {Name: "foo", UID: "bar"},
+	}
+	tokenAuthenticator := kauthenticator.TokenFunc(func(ctx context.Context, token string) (*kauthenticator.Response, bool, error) {
+		if token == "broken" {
+			return nil, false, errors.New("lookup failed")
+		}
+		user, ok := tokens[token]
+		if !ok {
+			return nil, false, nil
+		}
+		return &kauthenticator.Response{User: user}, true, nil
+	})
+
+	users := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := users.Add(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}, FullName: "Foo Bar"}); err != nil {
+		t.Fatal(err)
+	}
+	userInfoHandler := NewHandler(tokenAuthenticator, userlisters.NewUserLister(users))
+
+	for _, test := range []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedClaims *claims
+	}{
+		{
+			name:           "full scope",
+			authorization:  "Bearer full",
+			expectedStatus: http.StatusOK,
+			expectedClaims: &claims{Subject: "bar", PreferredUsername: "foo", Name: "Foo Bar", Groups: []string{"devs"}, Scopes: []string{"user:full"}},
+		},
+		{
+			name:           "info scope",
+			authorization:  "Bearer info",
+			expectedStatus: http.StatusOK,
+			expectedClaims: &claims{Subject: "bar", PreferredUsername: "foo", Name: "Foo Bar", Groups: []string{}, Scopes: []string{"user:info"}},
+		},
+		{
+			name:           "unscoped",
+			authorization:  "Bearer noscope",
+			expectedStatus: http.StatusOK,
+			expectedClaims: &claims{Subject: "bar", PreferredUsername: "foo", Name: "Foo Bar", Groups: []string{}, Scopes: []string{}},
+		},
+		{
+			name:           "insufficient scope",
+			authorization:  "Bearer check",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown token",
+			authorization:  "Bearer unknown",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed lookup",
+			authorization:  "Bearer broken",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "no token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Path, nil)
+			if len(test.authorization) > 0 {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			// authentication removes the header before the request reaches the handler
+			handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				req.Header.Del("Authorization")
+				userInfoHandler.ServeHTTP(w, req)
+			})
+
+			recorder := httptest.NewRecorder()
+			WithBearerToken(handler).ServeHTTP(recorder, req)
+
+			if recorder.Code != test.expectedStatus {
+				t.Fatalf("expected status %d, got %d", test.expectedStatus, recorder.Code)
+			}
+			if test.expectedClaims == nil {
+				return
+			}
+			got := &claims{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.expectedClaims) {
+				t.Errorf("expected %#v, got %#v", test.expectedClaims, got)
+			}
+		})
+	}
+}
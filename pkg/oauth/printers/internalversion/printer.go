@@ -128,9 +128,11 @@ func addOAuthAccessToken(h printers.PrintHandler) {
 		{Name: "User Name", Type: "string", Format: "name", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["userName"]},
 		{Name: "Client Name", Type: "string", Format: "name", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["clientName"]},
 		{Name: "Created", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		{Name: "Last Used", Type: "string", Description: "When the token was last used to authenticate, with a resolution of minutes."},
 		{Name: "Expires", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["expiresIn"]},
 		{Name: "Redirect URI", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["redirectURI"]},
 		{Name: "Scopes", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["scopes"]},
+		{Name: "Description", Type: "string", Description: "The description of the token."},
 	}
 	if err := h.TableHandler(oauthClientColumnsDefinitions, printOAuthAccessToken); err != nil {
 		panic(err)
@@ -145,9 +147,11 @@ func addUserOAuthAccessToken(h printers.PrintHandler) {
 		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
 		{Name: "Client Name", Type: "string", Format: "name", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["clientName"]},
 		{Name: "Created", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		{Name: "Last Used", Type: "string", Description: "When the token was last used to authenticate, with a resolution of minutes."},
 		{Name: "Expires", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["expiresIn"]},
 		{Name: "Redirect URI", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["redirectURI"]},
 		{Name: "Scopes", Type: "string", Description: oauthv1.OAuthAccessToken{}.SwaggerDoc()["scopes"]},
+		{Name: "Description", Type: "string", Description: "The description of the token."},
 	}
 	if err := h.TableHandler(userOAuthTokenColumnsDefinitions, printUserOAuthAccessToken); err != nil {
 		panic(err)
@@ -171,9 +175,11 @@ func printOAuthAccessToken(oauthAccessToken *oauthapi.OAuthAccessToken, options
 		oauthAccessToken.UserName,
 		oauthAccessToken.ClientName,
 		translateTimestampSince(created),
+		lastUsed(oauthAccessToken.Annotations),
 		expires,
 		oauthAccessToken.RedirectURI,
 		strings.Join(oauthAccessToken.Scopes, ","),
+		oauthAccessToken.Annotations[oauthapi.DescriptionAnnotation],
 	)
 	return []metav1.TableRow{row}, nil
 }
@@ -191,9 +197,11 @@ func printUserOAuthAccessToken(personalAccessToken *oauthapi.UserOAuthAccessToke
 		personalAccessToken.Name,
 		personalAccessToken.ClientName,
 		translateTimestampSince(created),
+		lastUsed(personalAccessToken.Annotations),
 		expires,
 		personalAccessToken.RedirectURI,
 		strings.Join(personalAccessToken.Scopes, ","),
+		personalAccessToken.Annotations[oauthapi.DescriptionAnnotation],
 	)
 	return []metav1.TableRow{row}, nil
 }
@@ -283,3 +291,16 @@ func translateTimestampSince(timestamp metav1.Time) string {
 
 	return duration.HumanDuration(time.Since(timestamp.Time))
 }
+
+// lastUsed formats the last use of a token recorded in its annotations
+func lastUsed(annotations map[string]string) string {
+	value, ok := annotations[oauthapi.LastUsedAnnotation]
+	if !ok {
+		return "<none>"
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "<unknown>"
+	}
+	return translateTimestampSince(metav1.NewTime(timestamp))
+}
@@ -2,54 +2,83 @@ package tokenvalidation
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"fmt"
-	"strings"
+	"sync"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
 	kuser "k8s.io/apiserver/pkg/authentication/user"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	authorizationv1 "github.com/openshift/api/authorization/v1"
 	userv1 "github.com/openshift/api/user/v1"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
 )
 
 const ClusterAdminGroup = "system:cluster-admins"
 
+// bootstrapUserSecretName is the name of the secret in kube-system the bootstrap user data is read from
+const bootstrapUserSecretName = "kubeadmin"
+
 type bootstrapAuthenticator struct {
 	tokens            oauthclient.OAuthAccessTokenInterface
 	getter            bootstrap.BootstrapUserDataGetter
 	validator         OAuthTokenValidator
 	implicitAudiences kauthenticator.Audiences
+
+	// rotationGracePeriod is how long tokens issued against the previous
+	// state of the bootstrap user secret keep working after it changed
+	rotationGracePeriod time.Duration
+	// changeTime returns when the bootstrap user secret last changed
+	changeTime func() time.Time
+	clock      clock.Clock
+
+	lock        sync.Mutex
+	currentUID  string
+	previousUID string
+	rotated     time.Time
 }
 
-func NewBootstrapAuthenticator(tokens oauthclient.OAuthAccessTokenInterface, getter bootstrap.BootstrapUserDataGetter, implicitAudiences kauthenticator.Audiences, validators ...OAuthTokenValidator) kauthenticator.Token {
-	return &bootstrapAuthenticator{
-		tokens:            tokens,
-		getter:            getter,
-		validator:         OAuthTokenValidators(validators),
-		implicitAudiences: implicitAudiences,
+// NewBootstrapAuthenticator returns an authenticator for the tokens of the bootstrap user.
+// If rotationGracePeriod is not 0, tokens issued against the previous bootstrap user secret
+// keep working for that long after the secret changed. The grace period starts at the last
+// write of the secret recorded in its managed fields, so that all servers end it at the
+// same time. The previous secret is only remembered in memory though: a server must have
+// seen it to accept its tokens, and a server that restarts rejects them right away.
+func NewBootstrapAuthenticator(tokens oauthclient.OAuthAccessTokenInterface, getter bootstrap.BootstrapUserDataGetter, secrets corev1client.SecretsGetter, implicitAudiences kauthenticator.Audiences, rotationGracePeriod time.Duration, validators ...OAuthTokenValidator) kauthenticator.Token {
+	a := &bootstrapAuthenticator{
+		tokens:              tokens,
+		getter:              getter,
+		validator:           OAuthTokenValidators(validators),
+		implicitAudiences:   implicitAudiences,
+		rotationGracePeriod: rotationGracePeriod,
+		clock:               clock.RealClock{},
 	}
+	a.changeTime = func() time.Time {
+		return secretChangeTime(secrets.Secrets(metav1.NamespaceSystem), a.clock.Now())
+	}
+	return a
 }
 
 func (a *bootstrapAuthenticator) AuthenticateToken(ctx context.Context, name string) (*kauthenticator.Response, bool, error) {
-	if !strings.HasPrefix(name, sha256Prefix) {
-		// only complain about non-sha256 format if the token is really an existing
-		// OAuthAccessToken (and e.g. no service account which also has no sha256 prefix)
+	objectName, ok := tokenname.ObjectName(name)
+	if !ok {
+		// only complain about the old format if the token is really an existing
+		// OAuthAccessToken (and e.g. no service account which also has no hashing prefix)
 		_, err := a.tokens.Get(ctx, name, metav1.GetOptions{})
 		if err == nil {
 			return nil, false, errOldFormat
 		}
 		return nil, false, errLookup
 	}
-
-	withoutPrefix := strings.TrimPrefix(name, sha256Prefix)
-	h := sha256.Sum256([]byte(withoutPrefix))
-	name = sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
+	name = objectName
 
 	token, err := a.tokens.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -68,13 +97,14 @@ func (a *bootstrapAuthenticator) AuthenticateToken(ctx context.Context, name str
 	// this allows us to reuse existing validators
 	// since the uid is based on the secret, if the secret changes, all
 	// tokens issued for the bootstrap user before that change stop working
+	// once the rotation grace period is over
 	fakeUser := &userv1.User{
 		ObjectMeta: metav1.ObjectMeta{
-			UID: types.UID(data.UID),
+			UID: types.UID(a.acceptedUID(token.UserUID, data.UID)),
 		},
 	}
 
-	if err := a.validator.Validate(token, fakeUser); err != nil {
+	if err := ValidateWithContext(ctx, a.validator, token, fakeUser); err != nil {
 		return nil, false, err
 	}
 
@@ -113,7 +143,51 @@ func (a *bootstrapAuthenticator) AuthenticateToken(ctx context.Context, name str
 			Extra: map[string][]string{
 				// this user still needs scopes because it can be used in OAuth flows (unlike cert based users)
 				authorizationv1.ScopesKey: token.Scopes,
+				kuser.CredentialIDKey:     {tokenname.CredentialID(token.Name)},
 			},
 		},
 	}, true, nil
 }
+
+// acceptedUID records changes of the bootstrap user secret and returns the UID
+// a token with tokenUID has to match in order to be valid.
+func (a *bootstrapAuthenticator) acceptedUID(tokenUID, currentUID string) string {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if currentUID != a.currentUID {
+		if len(a.currentUID) > 0 {
+			a.previousUID = a.currentUID
+			a.rotated = a.changeTime()
+		}
+		a.currentUID = currentUID
+	}
+
+	now := a.clock.Now()
+
+	if a.rotationGracePeriod > 0 && len(a.previousUID) > 0 && tokenUID == a.previousUID && now.Before(a.rotated.Add(a.rotationGracePeriod)) {
+		klog.V(2).Infof("Accepting %s token issued before the secret changed at %s", bootstrap.BootstrapUser, a.rotated)
+		return a.previousUID
+	}
+	return currentUID
+}
+
+// secretChangeTime returns the last write of the bootstrap user secret, which the API server
+// records in the managed fields of the writer. It falls back to now if the time is unknown.
+func secretChangeTime(secrets corev1client.SecretInterface, now time.Time) time.Time {
+	secret, err := secrets.Get(context.TODO(), bootstrapUserSecretName, metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("Failed to get the %s secret, starting the rotation grace period now: %v", bootstrapUserSecretName, err)
+		return now
+	}
+	var changed time.Time
+	for _, entry := range secret.ManagedFields {
+		if entry.Time != nil && entry.Time.After(changed) {
+			changed = entry.Time.Time
+		}
+	}
+	if changed.IsZero() || changed.After(now) {
+		return now
+	}
+	return changed
+}
@@ -0,0 +1,87 @@
+package tokenvalidation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestBootstrapAuthenticatorAcceptedUID(t *testing.T) {
+	testClock := clocktesting.NewFakeClock(time.Now())
+	a := &bootstrapAuthenticator{rotationGracePeriod: time.Minute, changeTime: testClock.Now, clock: testClock}
+
+	if got := a.acceptedUID("old", "old"); got != "old" {
+		t.Fatalf("expected the current UID, got %q", got)
+	}
+
+	// the secret changes, tokens of the previous secret are still accepted
+	if got := a.acceptedUID("old", "new"); got != "old" {
+		t.Errorf("expected the previous UID within the grace period, got %q", got)
+	}
+	if got := a.acceptedUID("new", "new"); got != "new" {
+		t.Errorf("expected the current UID, got %q", got)
+	}
+	if got := a.acceptedUID("other", "new"); got != "new" {
+		t.Errorf("expected the current UID for unknown tokens, got %q", got)
+	}
+
+	testClock.Step(time.Minute)
+	if got := a.acceptedUID("old", "new"); got != "new" {
+		t.Errorf("expected the current UID after the grace period, got %q", got)
+	}
+}
+
+func TestBootstrapAuthenticatorAcceptedUIDFromChangeTime(t *testing.T) {
+	testClock := clocktesting.NewFakeClock(time.Now())
+	changed := testClock.Now()
+	a := &bootstrapAuthenticator{rotationGracePeriod: time.Minute, changeTime: func() time.Time { return changed }, clock: testClock}
+
+	a.acceptedUID("old", "old")
+
+	// the server only sees the change 50 seconds after the secret changed
+	testClock.Step(50 * time.Second)
+	if got := a.acceptedUID("old", "new"); got != "old" {
+		t.Errorf("expected the previous UID within the grace period, got %q", got)
+	}
+	testClock.Step(10 * time.Second)
+	if got := a.acceptedUID("old", "new"); got != "new" {
+		t.Errorf("expected the grace period to end a minute after the secret changed, got %q", got)
+	}
+}
+
+func TestBootstrapAuthenticatorAcceptedUIDWithoutGracePeriod(t *testing.T) {
+	testClock := clocktesting.NewFakeClock(time.Now())
+	a := &bootstrapAuthenticator{changeTime: testClock.Now, clock: testClock}
+
+	a.acceptedUID("old", "old")
+	if got := a.acceptedUID("old", "new"); got != "new" {
+		t.Errorf("expected tokens of the previous secret to stop working immediately, got %q", got)
+	}
+}
+
+func TestSecretChangeTime(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-24 * time.Hour))
+	rotated := metav1.NewTime(now.Add(-time.Hour))
+	secrets := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      bootstrapUserSecretName,
+		Namespace: metav1.NamespaceSystem,
+		ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: "installer", Time: &created},
+			{Manager: "admin", Time: &rotated},
+		},
+	}}).CoreV1().Secrets(metav1.NamespaceSystem)
+
+	if got := secretChangeTime(secrets, now); !got.Equal(rotated.Time) {
+		t.Errorf("expected the last write of the secret, got %v", got)
+	}
+
+	missing := fake.NewSimpleClientset().CoreV1().Secrets(metav1.NamespaceSystem)
+	if got := secretChangeTime(missing, now); !got.Equal(now) {
+		t.Errorf("expected now without a secret, got %v", got)
+	}
+}
@@ -0,0 +1,186 @@
+package tokenvalidation
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	userclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+// The steps of the OAuth access token authentication that can reject a token.
+// Validators report the name they were given with NamedValidator.
+const (
+	DiagnosisStepTokenFormat = "TokenFormat"
+	DiagnosisStepTokenLookup = "TokenLookup"
+	DiagnosisStepUserLookup  = "UserLookup"
+	DiagnosisStepAudiences   = "Audiences"
+	// DiagnosisStepJWTAccessToken rejects JWT access tokens, which are not OAuthAccessTokens
+	DiagnosisStepJWTAccessToken = "JWTAccessToken"
+	// DiagnosisStepBootstrapUser rejects the tokens of the bootstrap user, which has no User object
+	DiagnosisStepBootstrapUser = "BootstrapUser"
+)
+
+// Diagnosis tells whether an OAuth access token authenticates and otherwise
+// which step rejected it and why. Unlike the errors of the token authenticator,
+// the reasons are not masked and must only be shown to privileged users.
+type Diagnosis struct {
+	Authenticated bool
+	// Step is the step that rejected the token, empty if it authenticates
+	Step   string
+	Reason string
+	// TokenName and UserName are set as soon as they are known
+	TokenName string
+	UserName  string
+}
+
+// TokenDiagnoser walks through the same steps as the OAuth access token authenticator,
+// but stops at the first failing step to report it instead of masking the error.
+// It does not record token usage, diagnosing a token does not extend its timeout.
+// Tokens of the bootstrap user and JWT access tokens are diagnosed the way the bootstrap
+// and the JWT access token authenticators check them.
+type TokenDiagnoser struct {
+	tokens         oauthclient.OAuthAccessTokenInterface
+	users          userclient.UserInterface
+	bootstrapUsers bootstrap.BootstrapUserDataGetter
+	jwtTokens      kauthenticator.Token
+	validators     []OAuthTokenValidator
+	implicitAuds   kauthenticator.Audiences
+}
+
+// NewTokenDiagnoser returns a diagnoser of the tokens the authenticators of the server accept.
+// bootstrapUsers is nil if the bootstrap authenticator is disabled, jwtTokens is the JWT access
+// token authenticator, nil if JWT access tokens are not accepted. The JWT access token authenticator
+// must not record token usage either.
+func NewTokenDiagnoser(tokens oauthclient.OAuthAccessTokenInterface, users userclient.UserInterface, bootstrapUsers bootstrap.BootstrapUserDataGetter, jwtTokens kauthenticator.Token, implicitAuds kauthenticator.Audiences, validators ...OAuthTokenValidator) *TokenDiagnoser {
+	return &TokenDiagnoser{
+		tokens:         tokens,
+		users:          users,
+		bootstrapUsers: bootstrapUsers,
+		jwtTokens:      jwtTokens,
+		validators:     validators,
+		implicitAuds:   implicitAuds,
+	}
+}
+
+// DiagnoseToken diagnoses a token as sent by a client.
+func (d *TokenDiagnoser) DiagnoseToken(ctx context.Context, token string, audiences []string) *Diagnosis {
+	if diagnosis, ok := d.diagnoseJWT(ctx, token, audiences); ok {
+		return diagnosis
+	}
+	name, ok := tokenname.ObjectName(token)
+	if !ok {
+		if _, err := d.tokens.Get(ctx, token, metav1.GetOptions{}); err == nil {
+			return &Diagnosis{Step: DiagnosisStepTokenFormat, Reason: errOldFormat.Error()}
+		}
+		return &Diagnosis{Step: DiagnosisStepTokenFormat, Reason: fmt.Sprintf("token has none of the supported prefixes %q", tokenname.Prefixes())}
+	}
+	return d.DiagnoseTokenName(ctx, name, audiences)
+}
+
+// DiagnoseTokenName diagnoses a token by the name of its OAuthAccessToken object,
+// so that the token itself does not need to be handed over.
+func (d *TokenDiagnoser) DiagnoseTokenName(ctx context.Context, name string, audiences []string) *Diagnosis {
+	diagnosis := &Diagnosis{TokenName: name}
+	if !tokenname.HasSupportedPrefix(name) {
+		diagnosis.Step, diagnosis.Reason = DiagnosisStepTokenFormat, fmt.Sprintf("token name has none of the supported prefixes %q", tokenname.Prefixes())
+		return diagnosis
+	}
+
+	token, err := d.tokens.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		diagnosis.Step, diagnosis.Reason = DiagnosisStepTokenLookup, "token does not exist, it was never issued, was deleted or has been garbage collected after expiring"
+		return diagnosis
+	}
+	if err != nil {
+		diagnosis.Step, diagnosis.Reason = DiagnosisStepTokenLookup, err.Error()
+		return diagnosis
+	}
+	diagnosis.UserName = token.UserName
+
+	user, step, err := d.lookupUser(ctx, token)
+	if err != nil {
+		diagnosis.Step, diagnosis.Reason = step, err.Error()
+		return diagnosis
+	}
+
+	for i, validator := range d.validators {
+		step := fmt.Sprintf("Validator%d", i)
+		if named, ok := validator.(NamedOAuthTokenValidator); ok {
+			step, validator = named.Name, named.OAuthTokenValidator
+		}
+		validate := validator.Validate
+		if sideEffectFree, ok := validator.(sideEffectFreeValidator); ok {
+			validate = sideEffectFree.ValidateOnly
+		}
+		if err := validate(token, user); err != nil {
+			diagnosis.Step, diagnosis.Reason = step, err.Error()
+			return diagnosis
+		}
+	}
+
+	requestedAudiences := kauthenticator.Audiences(audiences)
+	if len(requestedAudiences) == 0 {
+		requestedAudiences = d.implicitAuds
+	}
+	if len(d.implicitAuds) != 0 && len(d.implicitAuds.Intersect(requestedAudiences)) == 0 {
+		diagnosis.Step, diagnosis.Reason = DiagnosisStepAudiences, fmt.Sprintf("token audiences %q is invalid for the target audiences %q", d.implicitAuds, requestedAudiences)
+		return diagnosis
+	}
+
+	diagnosis.Authenticated = true
+	return diagnosis
+}
+
+// diagnoseJWT diagnoses JWT access tokens with the JWT access token authenticator,
+// false if the token is not one.
+func (d *TokenDiagnoser) diagnoseJWT(ctx context.Context, token string, audiences []string) (*Diagnosis, bool) {
+	if d.jwtTokens == nil {
+		return nil, false
+	}
+	if len(audiences) > 0 {
+		ctx = kauthenticator.WithAudiences(ctx, audiences)
+	}
+	resp, ok, err := d.jwtTokens.AuthenticateToken(ctx, token)
+	switch {
+	case err != nil:
+		return &Diagnosis{Step: DiagnosisStepJWTAccessToken, Reason: err.Error()}, true
+	case !ok:
+		// not a JWT access token of this server
+		return nil, false
+	default:
+		return &Diagnosis{Authenticated: true, UserName: resp.User.GetName()}, true
+	}
+}
+
+// lookupUser returns the user of the token and otherwise the step that failed. The bootstrap
+// user has no User object, its UID is derived from its secret. Tokens of the bootstrap user
+// that are only accepted during the rotation grace period of its secret are reported as rejected.
+func (d *TokenDiagnoser) lookupUser(ctx context.Context, token *oauthv1.OAuthAccessToken) (*userv1.User, string, error) {
+	if token.UserName != bootstrap.BootstrapUser {
+		user, err := d.users.Get(ctx, token.UserName, metav1.GetOptions{})
+		return user, DiagnosisStepUserLookup, err
+	}
+
+	if d.bootstrapUsers == nil {
+		return nil, DiagnosisStepBootstrapUser, fmt.Errorf("the bootstrap authenticator is disabled, tokens of %s are not accepted", bootstrap.BootstrapUser)
+	}
+	data, ok, err := d.bootstrapUsers.Get()
+	if err != nil {
+		return nil, DiagnosisStepBootstrapUser, err
+	}
+	if !ok {
+		return nil, DiagnosisStepBootstrapUser, fmt.Errorf("the secret of %s does not exist, it was removed", bootstrap.BootstrapUser)
+	}
+	return &userv1.User{ObjectMeta: metav1.ObjectMeta{UID: types.UID(data.UID)}}, "", nil
+}
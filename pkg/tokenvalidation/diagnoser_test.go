@@ -0,0 +1,205 @@
+package tokenvalidation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+	bootstrap "github.com/openshift/library-go/pkg/authentication/bootstrapauthenticator"
+)
+
+func TestDiagnoseToken(t *testing.T) {
+	validToken, validName := generateOAuthTokenPair()
+	expiredToken, expiredName := generateOAuthTokenPair()
+	otherUIDToken, otherUIDName := generateOAuthTokenPair()
+	noUserToken, noUserName := generateOAuthTokenPair()
+	unknownToken, unknownName := generateOAuthTokenPair()
+
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: validName, CreationTimestamp: created}, ExpiresIn: 7200, UserName: "foo", UserUID: "bar"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: expiredName, CreationTimestamp: created}, ExpiresIn: 600, UserName: "foo", UserUID: "bar"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: otherUIDName, CreationTimestamp: created}, UserName: "foo", UserUID: "old"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: noUserName, CreationTimestamp: created}, UserName: "deleted"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "oldformat", CreationTimestamp: created}, UserName: "foo"},
+	)
+	fakeUserClient := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}})
+
+	diagnoser := NewTokenDiagnoser(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(), nil, nil, authenticator.Audiences{"api"},
+		NamedValidator("Expiration", NewExpirationValidator()),
+		NamedValidator("UserUID", NewUIDValidator()),
+	)
+
+	for _, test := range []struct {
+		name              string
+		token             string
+		tokenName         string
+		audiences         []string
+		expectedStep      string
+		expectedUserName  string
+		expectedTokenName string
+	}{
+		{name: "valid", token: validToken, expectedUserName: "foo", expectedTokenName: validName},
+		{name: "valid by name", tokenName: validName, expectedUserName: "foo", expectedTokenName: validName},
+		{name: "old format", token: "oldformat", expectedStep: DiagnosisStepTokenFormat},
+		{name: "no prefix", token: "garbage", expectedStep: DiagnosisStepTokenFormat},
+		{name: "name without prefix", tokenName: "garbage", expectedStep: DiagnosisStepTokenFormat, expectedTokenName: "garbage"},
+		{name: "unknown", token: unknownToken, expectedStep: DiagnosisStepTokenLookup, expectedTokenName: unknownName},
+		{name: "deleted user", token: noUserToken, expectedStep: DiagnosisStepUserLookup, expectedUserName: "deleted", expectedTokenName: noUserName},
+		{name: "expired", token: expiredToken, expectedStep: "Expiration", expectedUserName: "foo", expectedTokenName: expiredName},
+		{name: "uid mismatch", token: otherUIDToken, expectedStep: "UserUID", expectedUserName: "foo", expectedTokenName: otherUIDName},
+		{name: "other audience", token: validToken, audiences: []string{"other"}, expectedStep: DiagnosisStepAudiences, expectedUserName: "foo", expectedTokenName: validName},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var diagnosis *Diagnosis
+			if len(test.token) > 0 {
+				diagnosis = diagnoser.DiagnoseToken(context.TODO(), test.token, test.audiences)
+			} else {
+				diagnosis = diagnoser.DiagnoseTokenName(context.TODO(), test.tokenName, test.audiences)
+			}
+
+			if diagnosis.Authenticated != (len(test.expectedStep) == 0) {
+				t.Errorf("unexpected authenticated %v: %#v", diagnosis.Authenticated, diagnosis)
+			}
+			if diagnosis.Step != test.expectedStep {
+				t.Errorf("expected step %q, got %q", test.expectedStep, diagnosis.Step)
+			}
+			if len(test.expectedStep) > 0 && len(diagnosis.Reason) == 0 {
+				t.Error("expected a reason")
+			}
+			if diagnosis.UserName != test.expectedUserName || diagnosis.TokenName != test.expectedTokenName {
+				t.Errorf("expected token %q of user %q, got token %q of user %q", test.expectedTokenName, test.expectedUserName, diagnosis.TokenName, diagnosis.UserName)
+			}
+		})
+	}
+}
+
+type fakeBootstrapUsers struct {
+	data *bootstrap.BootstrapUserData
+}
+
+func (f fakeBootstrapUsers) Get() (*bootstrap.BootstrapUserData, bool, error) {
+	return f.data, f.data != nil, nil
+}
+
+func (f fakeBootstrapUsers) IsEnabled() (bool, error) {
+	return f.data != nil, nil
+}
+
+func TestDiagnoseOtherTokenTypes(t *testing.T) {
+	bootstrapToken, bootstrapName := generateOAuthTokenPair()
+	oldBootstrapToken, oldBootstrapName := generateOAuthTokenPair()
+
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: bootstrapName, CreationTimestamp: created}, ExpiresIn: 7200, UserName: bootstrap.BootstrapUser, UserUID: "secret"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: oldBootstrapName, CreationTimestamp: created}, ExpiresIn: 7200, UserName: bootstrap.BootstrapUser, UserUID: "old-secret"},
+	)
+	// the user lookup must not be used for the bootstrap user
+	fakeUserClient := userfake.NewSimpleClientset()
+
+	jwtTokens := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		switch token {
+		case "header.valid.signature":
+			return &authenticator.Response{User: &kuser.DefaultInfo{Name: "foo"}}, true, nil
+		case "header.revoked.signature":
+			return nil, false, errors.New("JWT access token was revoked")
+		default:
+			return nil, false, nil
+		}
+	})
+
+	for _, test := range []struct {
+		name              string
+		bootstrapUsers    bootstrap.BootstrapUserDataGetter
+		jwtTokens         authenticator.Token
+		token             string
+		expectedStep      string
+		expectedUserName  string
+		expectedTokenName string
+	}{
+		{
+			name:              "bootstrap user",
+			bootstrapUsers:    fakeBootstrapUsers{data: &bootstrap.BootstrapUserData{UID: "secret"}},
+			token:             bootstrapToken,
+			expectedUserName:  bootstrap.BootstrapUser,
+			expectedTokenName: bootstrapName,
+		},
+		{
+			name:              "bootstrap user secret changed",
+			bootstrapUsers:    fakeBootstrapUsers{data: &bootstrap.BootstrapUserData{UID: "secret"}},
+			token:             oldBootstrapToken,
+			expectedStep:      "UserUID",
+			expectedUserName:  bootstrap.BootstrapUser,
+			expectedTokenName: oldBootstrapName,
+		},
+		{
+			name:              "bootstrap user removed",
+			bootstrapUsers:    fakeBootstrapUsers{},
+			token:             bootstrapToken,
+			expectedStep:      DiagnosisStepBootstrapUser,
+			expectedUserName:  bootstrap.BootstrapUser,
+			expectedTokenName: bootstrapName,
+		},
+		{
+			name:              "bootstrap authenticator disabled",
+			token:             bootstrapToken,
+			expectedStep:      DiagnosisStepBootstrapUser,
+			expectedUserName:  bootstrap.BootstrapUser,
+			expectedTokenName: bootstrapName,
+		},
+		{
+			name:             "JWT access token",
+			jwtTokens:        jwtTokens,
+			token:            "header.valid.signature",
+			expectedUserName: "foo",
+		},
+		{
+			name:         "revoked JWT access token",
+			jwtTokens:    jwtTokens,
+			token:        "header.revoked.signature",
+			expectedStep: DiagnosisStepJWTAccessToken,
+		},
+		{
+			name:         "other JWT",
+			jwtTokens:    jwtTokens,
+			token:        "header.other.signature",
+			expectedStep: DiagnosisStepTokenFormat,
+		},
+		{
+			name:         "JWT access tokens disabled",
+			token:        "header.valid.signature",
+			expectedStep: DiagnosisStepTokenFormat,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			diagnoser := NewTokenDiagnoser(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(), test.bootstrapUsers, test.jwtTokens, authenticator.Audiences{"api"},
+				NamedValidator("Expiration", NewExpirationValidator()),
+				NamedValidator("UserUID", NewUIDValidator()),
+			)
+			diagnosis := diagnoser.DiagnoseToken(context.TODO(), test.token, nil)
+
+			if diagnosis.Authenticated != (len(test.expectedStep) == 0) {
+				t.Errorf("unexpected authenticated %v: %#v", diagnosis.Authenticated, diagnosis)
+			}
+			if diagnosis.Step != test.expectedStep {
+				t.Errorf("expected step %q, got %q: %s", test.expectedStep, diagnosis.Step, diagnosis.Reason)
+			}
+			if len(test.expectedStep) > 0 && len(diagnosis.Reason) == 0 {
+				t.Error("expected a reason")
+			}
+			if diagnosis.UserName != test.expectedUserName || diagnosis.TokenName != test.expectedTokenName {
+				t.Errorf("expected token %q of user %q, got token %q of user %q", test.expectedTokenName, test.expectedUserName, diagnosis.TokenName, diagnosis.UserName)
+			}
+		})
+	}
+}
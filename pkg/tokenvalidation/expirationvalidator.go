@@ -6,9 +6,14 @@ import (
 
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	userv1 "github.com/openshift/api/user/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
 )
 
-var errExpired = errors.New("token is expired")
+var (
+	errExpired        = errors.New("token is expired")
+	errSessionExpired = errors.New("session is expired")
+)
 
 func NewExpirationValidator() OAuthTokenValidator {
 	return OAuthTokenValidatorFunc(
@@ -29,3 +34,44 @@ func NewExpirationValidator() OAuthTokenValidator {
 func expire(token *oauthv1.OAuthAccessToken) time.Time {
 	return token.CreationTimestamp.Add(time.Duration(token.ExpiresIn) * time.Second)
 }
+
+// NewMaxAgeValidator rejects tokens that are older than the strictest
+// maximum age the session policy sets for their user.
+func NewMaxAgeValidator(sessionPolicy *SessionPolicyEvaluator) OAuthTokenValidator {
+	return OAuthTokenValidatorFunc(
+		func(token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+			limits, err := sessionPolicy.LimitsFor(user)
+			if err != nil {
+				return err
+			}
+			if limits.MaxAge > 0 && token.CreationTimestamp.Add(limits.MaxAge).Before(time.Now()) {
+				return errExpired
+			}
+			return nil
+		},
+	)
+}
+
+// NewSessionLifetimeValidator rejects tokens whose session started more than
+// lifetime ago, no matter how many times the client got a new token since then.
+func NewSessionLifetimeValidator(lifetime time.Duration) OAuthTokenValidator {
+	return OAuthTokenValidatorFunc(
+		func(token *oauthv1.OAuthAccessToken, _ *userv1.User) error {
+			if lifetime > 0 && sessionStart(token).Add(lifetime).Before(time.Now()) {
+				return errSessionExpired
+			}
+			return nil
+		},
+	)
+}
+
+// sessionStart returns the start of the session the token belongs to,
+// tokens without a valid session start annotation start their own session
+func sessionStart(token *oauthv1.OAuthAccessToken) time.Time {
+	if value, ok := token.Annotations[oauthapi.SessionStartAnnotation]; ok {
+		if start, err := time.Parse(time.RFC3339, value); err == nil {
+			return start
+		}
+	}
+	return token.CreationTimestamp.Time
+}
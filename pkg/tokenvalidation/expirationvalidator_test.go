@@ -15,6 +15,9 @@ import (
 	userv1 "github.com/openshift/api/user/v1"
 	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
 	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
 )
 
 func TestAuthenticateTokenExpired(t *testing.T) {
@@ -78,6 +81,81 @@ func TestAuthenticateTokenValidated(t *testing.T) {
 	}
 }
 
+func TestAuthenticateTokenMaxAge(t *testing.T) {
+	token, tokenHash := generateOAuthTokenPair()
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{
+			ObjectMeta: metav1.ObjectMeta{Name: tokenHash, CreationTimestamp: metav1.Time{Time: time.Now().Add(-20 * time.Minute)}},
+			ExpiresIn:  86400,
+			UserName:   "foo",
+		},
+	)
+	policy := &sessionpolicy.SessionPolicy{
+		MinimumInactivityTimeoutSeconds: sessionpolicy.DefaultMinimumInactivityTimeoutSeconds,
+		Rules: []sessionpolicy.Rule{
+			{Name: "break-glass", IdentityProviders: []string{"break-glass"}, MaxAgeSeconds: 600},
+		},
+	}
+
+	for _, tc := range []struct {
+		identity string
+		wantErr  error
+	}{
+		{identity: "break-glass:foo", wantErr: errExpired},
+		{identity: "ldap:foo", wantErr: nil},
+	} {
+		fakeUserClient := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}, Identities: []string{tc.identity}})
+		evaluator := NewSessionPolicyEvaluator(sessionpolicy.Static(policy), NoopGroupMapper{})
+		tokenAuthenticator := NewTokenAuthenticator(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(), NoopGroupMapper{}, nil, NewExpirationValidator(), NewMaxAgeValidator(evaluator))
+
+		_, found, err := tokenAuthenticator.AuthenticateToken(context.TODO(), token)
+		if err != tc.wantErr {
+			t.Errorf("identity %s: expected error %v, got %v", tc.identity, tc.wantErr, err)
+		}
+		if found != (tc.wantErr == nil) {
+			t.Errorf("identity %s: unexpected found=%v", tc.identity, found)
+		}
+	}
+}
+
+func TestSessionLifetimeValidator(t *testing.T) {
+	now := time.Now()
+	validator := NewSessionLifetimeValidator(24 * time.Hour)
+
+	for name, tc := range map[string]struct {
+		token   *oauthv1.OAuthAccessToken
+		wantErr error
+	}{
+		"new token from an old session": {
+			token: &oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Time{Time: now},
+				Annotations:       map[string]string{oauthapi.SessionStartAnnotation: now.Add(-25 * time.Hour).Format(time.RFC3339)},
+			}},
+			wantErr: errSessionExpired,
+		},
+		"new token from a recent session": {
+			token: &oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Time{Time: now},
+				Annotations:       map[string]string{oauthapi.SessionStartAnnotation: now.Add(-23 * time.Hour).Format(time.RFC3339)},
+			}},
+		},
+		"old token without session start": {
+			token: &oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Time{Time: now.Add(-25 * time.Hour)},
+			}},
+			wantErr: errSessionExpired,
+		},
+	} {
+		if err := validator.Validate(tc.token, &userv1.User{}); err != tc.wantErr {
+			t.Errorf("%s: expected error %v, got %v", name, tc.wantErr, err)
+		}
+	}
+
+	if err := NewSessionLifetimeValidator(0).Validate(&oauthv1.OAuthAccessToken{}, &userv1.User{}); err != nil {
+		t.Errorf("expected no session lifetime to allow all tokens, got %v", err)
+	}
+}
+
 // generateOAuthTokenPair returns two tokens to use with OpenShift OAuth-based authentication.
 // The first token is a private token meant to be used as a Bearer token to send
 // queries to the API, the second token is a hashed token meant to be stored in
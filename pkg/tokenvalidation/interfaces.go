@@ -1,6 +1,12 @@
 package tokenvalidation
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"k8s.io/component-base/tracing"
+
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	userv1 "github.com/openshift/api/user/v1"
 )
@@ -9,6 +15,22 @@ type OAuthTokenValidator interface {
 	Validate(token *oauthv1.OAuthAccessToken, user *userv1.User) error
 }
 
+// ContextOAuthTokenValidator is implemented by validators that make use of the
+// context of the request the token is validated for, e.g. to add audit annotations.
+type ContextOAuthTokenValidator interface {
+	OAuthTokenValidator
+	ValidateWithContext(ctx context.Context, token *oauthv1.OAuthAccessToken, user *userv1.User) error
+}
+
+// ValidateWithContext validates the token with the context of the request
+// if the validator makes use of it.
+func ValidateWithContext(ctx context.Context, validator OAuthTokenValidator, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	if contextValidator, ok := validator.(ContextOAuthTokenValidator); ok {
+		return contextValidator.ValidateWithContext(ctx, token, user)
+	}
+	return validator.Validate(token, user)
+}
+
 var _ OAuthTokenValidator = OAuthTokenValidatorFunc(nil)
 
 type OAuthTokenValidatorFunc func(token *oauthv1.OAuthAccessToken, user *userv1.User) error
@@ -30,6 +52,15 @@ func (v OAuthTokenValidators) Validate(token *oauthv1.OAuthAccessToken, user *us
 	return nil
 }
 
+func (v OAuthTokenValidators) ValidateWithContext(ctx context.Context, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	for _, validator := range v {
+		if err := ValidateWithContext(ctx, validator, token, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type UserToGroupMapper interface {
 	GroupsFor(username string) ([]*userv1.Group, error)
 }
@@ -39,3 +70,53 @@ type NoopGroupMapper struct{}
 func (n NoopGroupMapper) GroupsFor(username string) ([]*userv1.Group, error) {
 	return []*userv1.Group{}, nil
 }
+
+// NamedOAuthTokenValidator is a validator with the name of the check it performs,
+// used to tell which validator rejected a token when diagnosing it.
+type NamedOAuthTokenValidator struct {
+	Name string
+	OAuthTokenValidator
+}
+
+func (v NamedOAuthTokenValidator) ValidateWithContext(ctx context.Context, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	ctx, span := tracing.Start(ctx, "Validate token", attribute.String("validator", v.Name))
+	defer span.End(authenticationTraceThreshold)
+
+	err := ValidateWithContext(ctx, v.OAuthTokenValidator, token, user)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// NamedValidator names validator for token diagnosis.
+func NamedValidator(name string, validator OAuthTokenValidator) OAuthTokenValidator {
+	return NamedOAuthTokenValidator{Name: name, OAuthTokenValidator: validator}
+}
+
+// sideEffectFreeValidator is implemented by validators that record token usage.
+// ValidateOnly runs the same checks as Validate without recording anything.
+type sideEffectFreeValidator interface {
+	ValidateOnly(token *oauthv1.OAuthAccessToken, user *userv1.User) error
+}
+
+// ValidateOnly validates the token without recording its use. Validators that record it
+// run their ValidateOnly checks instead. It is meant for tokens that are only looked at
+// and for tokens whose use cannot be recorded.
+func ValidateOnly(validator OAuthTokenValidator, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	switch v := validator.(type) {
+	case OAuthTokenValidators:
+		for _, validator := range v {
+			if err := ValidateOnly(validator, token, user); err != nil {
+				return err
+			}
+		}
+		return nil
+	case NamedOAuthTokenValidator:
+		return ValidateOnly(v.OAuthTokenValidator, token, user)
+	case sideEffectFreeValidator:
+		return v.ValidateOnly(token, user)
+	default:
+		return validator.Validate(token, user)
+	}
+}
@@ -0,0 +1,57 @@
+package tokenvalidation
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	userclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+// TokenIntrospector looks up OAuth access tokens and validates them with the same
+// validators as the token authenticator, but without authenticating a request.
+// Introspecting a token does not count as a use, it neither extends its inactivity
+// timeout nor updates its last use.
+type TokenIntrospector struct {
+	tokens     oauthclient.OAuthAccessTokenInterface
+	users      userclient.UserInterface
+	validators OAuthTokenValidator
+}
+
+func NewTokenIntrospector(tokens oauthclient.OAuthAccessTokenInterface, users userclient.UserInterface, validators ...OAuthTokenValidator) *TokenIntrospector {
+	return &TokenIntrospector{
+		tokens:     tokens,
+		users:      users,
+		validators: OAuthTokenValidators(validators),
+	}
+}
+
+// Introspect returns the token object of a valid token and its user.
+// The errors are masked so that they can be returned to clients.
+func (i *TokenIntrospector) Introspect(ctx context.Context, token string) (*oauthv1.OAuthAccessToken, *userv1.User, error) {
+	name, ok := tokenname.ObjectName(token)
+	if !ok {
+		return nil, nil, errOldFormat
+	}
+
+	accessToken, err := i.tokens.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errLookup // mask the error so we do not leak token data in logs
+	}
+
+	user, err := i.users.Get(ctx, accessToken.UserName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errLookup
+	}
+
+	if err := ValidateOnly(i.validators, accessToken, user); err != nil {
+		return nil, nil, err
+	}
+
+	return accessToken, user, nil
+}
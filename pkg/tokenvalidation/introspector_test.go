@@ -0,0 +1,55 @@
+package tokenvalidation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
+
+// recordingValidator records whether tokens were validated as used or only looked at
+type recordingValidator struct {
+	used, lookedAt int
+}
+
+func (v *recordingValidator) Validate(*oauthv1.OAuthAccessToken, *userv1.User) error {
+	v.used++
+	return nil
+}
+
+func (v *recordingValidator) ValidateOnly(*oauthv1.OAuthAccessToken, *userv1.User) error {
+	v.lookedAt++
+	return nil
+}
+
+// TestIntrospectDoesNotRecordUse asserts that introspecting a token does not count as a use,
+// which would extend its inactivity timeout and update its last use
+func TestIntrospectDoesNotRecordUse(t *testing.T) {
+	tokenName, _ := tokenname.ObjectName("sha256~someRandomTokenWhichIsLongEnough")
+	fakeOAuthClient := oauthfake.NewSimpleClientset(&oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: tokenName, CreationTimestamp: metav1.NewTime(time.Now())},
+		ClientName: "client",
+		UserName:   "foo",
+		UserUID:    "bar",
+	})
+	fakeUserClient := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}})
+
+	validator := &recordingValidator{}
+	introspector := NewTokenIntrospector(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(),
+		NamedValidator(UserUIDValidatorName, NewUIDValidator()), NamedValidator(InactivityTimeoutValidatorName, validator))
+
+	if _, _, err := introspector.Introspect(context.TODO(), "sha256~someRandomTokenWhichIsLongEnough"); err != nil {
+		t.Fatalf("expected the token to be active, got %v", err)
+	}
+	if validator.used != 0 || validator.lookedAt != 1 {
+		t.Errorf("expected the token to only be looked at, got %d uses and %d looks", validator.used, validator.lookedAt)
+	}
+}
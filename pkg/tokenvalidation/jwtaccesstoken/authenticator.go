@@ -0,0 +1,182 @@
+package jwtaccesstoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog/v2"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+)
+
+var validMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+var (
+	errInvalidToken = errors.New("invalid JWT access token")
+	errRevoked      = errors.New("JWT access token was revoked")
+	errLookup       = errors.New("token lookup failed")
+)
+
+// Claims are the claims of a JWT access token. The subject is the name of the user.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	UID      string   `json:"uid"`
+	Scopes   []string `json:"scopes,omitempty"`
+	ClientID string   `json:"client_id,omitempty"`
+	// AuthTime is the start of the session the token belongs to
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+}
+
+// accessToken describes the token as an OAuthAccessToken for the token validators. It is
+// created when it was issued and never times out from inactivity. Tokens without an auth_time
+// claim start their own session.
+func (c *Claims) accessToken() *oauthv1.OAuthAccessToken {
+	token := &oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: c.ID, CreationTimestamp: metav1.NewTime(c.IssuedAt.Time)},
+		ClientName: c.ClientID,
+		ExpiresIn:  int64(c.ExpiresAt.Sub(c.IssuedAt.Time) / time.Second),
+		Scopes:     c.Scopes,
+		UserName:   c.Subject,
+		UserUID:    c.UID,
+	}
+	if c.AuthTime != nil {
+		token.Annotations = map[string]string{oauthapi.SessionStartAnnotation: c.AuthTime.UTC().Format(time.RFC3339)}
+	}
+	return token
+}
+
+// Config configures the validation of JWT access tokens.
+type Config struct {
+	// Issuer is the "iss" claim of the accepted tokens
+	Issuer string
+	// PublicKeys are the keys the tokens may be signed with
+	PublicKeys []PublicKey
+	// JWKSURI is where the discovery metadata tells to find the public keys
+	JWKSURI string
+	// MaxLifetime is the longest time between "iat" and "exp" of accepted tokens
+	MaxLifetime time.Duration
+	// DenyList revokes tokens before they expire, may be nil
+	DenyList *DenyList
+}
+
+type jwtAuthenticator struct {
+	config       *Config
+	keys         map[string]PublicKey
+	users        userclient.UserInterface
+	groupMapper  tokenvalidation.UserToGroupMapper
+	implicitAuds kauthenticator.Audiences
+	validators   tokenvalidation.OAuthTokenValidator
+}
+
+// NewAuthenticator returns an authenticator of JWT access tokens. Tokens that are
+// not JWTs or that were issued by someone else are left to other authenticators.
+// Unlike sha256~ tokens, JWT access tokens are never looked up in storage, so the
+// validators run without recording their use: the validators of the inactivity
+// timeout and of the last use do not apply to them.
+func NewAuthenticator(config *Config, users userclient.UserInterface, groupMapper tokenvalidation.UserToGroupMapper, implicitAuds kauthenticator.Audiences, validators ...tokenvalidation.OAuthTokenValidator) kauthenticator.Token {
+	keys := make(map[string]PublicKey, len(config.PublicKeys))
+	for _, key := range config.PublicKeys {
+		keys[key.KeyID] = key
+	}
+
+	return &jwtAuthenticator{
+		config:       config,
+		keys:         keys,
+		users:        users,
+		groupMapper:  groupMapper,
+		implicitAuds: implicitAuds,
+		validators:   tokenvalidation.OAuthTokenValidators(validators),
+	}
+}
+
+func (a *jwtAuthenticator) AuthenticateToken(ctx context.Context, tokenData string) (*kauthenticator.Response, bool, error) {
+	if strings.Count(tokenData, ".") != 2 {
+		return nil, false, nil
+	}
+
+	unverified := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenData, unverified); err != nil || unverified.Issuer != a.config.Issuer {
+		// not one of ours, e.g. a service account token
+		return nil, false, nil
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenData, claims, a.keyFor,
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(a.config.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		klog.V(4).Infof("Rejected JWT access token: %v", err)
+		return nil, false, errInvalidToken
+	}
+	if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > a.config.MaxLifetime {
+		return nil, false, fmt.Errorf("JWT access token lifetime exceeds %s", a.config.MaxLifetime)
+	}
+	if a.config.DenyList.Denied(claims) {
+		return nil, false, errRevoked
+	}
+
+	user, err := a.users.Get(ctx, claims.Subject, metav1.GetOptions{})
+	if err != nil {
+		return nil, false, errLookup
+	}
+	if err := tokenvalidation.ValidateOnly(a.validators, claims.accessToken(), user); err != nil {
+		return nil, false, err
+	}
+
+	groups, err := a.groupMapper.GroupsFor(user.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	groupNames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name)
+	}
+
+	requestedAudiences, ok := kauthenticator.AudiencesFrom(ctx)
+	if !ok {
+		// default to apiserver audiences
+		requestedAudiences = a.implicitAuds
+	}
+
+	auds := kauthenticator.Audiences(claims.Audience).Intersect(requestedAudiences)
+	if len(auds) == 0 && len(requestedAudiences) != 0 {
+		return nil, false, fmt.Errorf("token audiences %q is invalid for the target audiences %q", []string(claims.Audience), requestedAudiences)
+	}
+
+	return &kauthenticator.Response{
+		User: &kuser.DefaultInfo{
+			Name:   user.Name,
+			UID:    string(user.UID),
+			Groups: groupNames,
+			Extra: map[string][]string{
+				authorizationv1.ScopesKey: claims.Scopes,
+			},
+		},
+		Audiences: auds,
+	}, true, nil
+}
+
+func (a *jwtAuthenticator) keyFor(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	if key, ok := a.keys[keyID]; ok {
+		return key.Key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
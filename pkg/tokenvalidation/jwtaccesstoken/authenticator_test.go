@@ -0,0 +1,268 @@
+package jwtaccesstoken
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+)
+
+const testIssuer = "https://oauth.example.com"
+
+func TestAuthenticateToken(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, err := KeyIDFromPublicKey(&signingKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denyListPath := filepath.Join(t.TempDir(), "denylist.yaml")
+	if err := os.WriteFile(denyListPath, []byte("tokenIDs: [\"revoked\"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	denyList, err := NewDenyList(denyListPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}}).UserV1().Users()
+	now := time.Now()
+
+	authenticator := NewAuthenticator(&Config{
+		Issuer:      testIssuer,
+		PublicKeys:  []PublicKey{{KeyID: keyID, Key: &signingKey.PublicKey}},
+		MaxLifetime: 15 * time.Minute,
+		DenyList:    denyList,
+	}, users, tokenvalidation.NoopGroupMapper{}, []string{"api"},
+		tokenvalidation.NewUIDValidator(),
+		tokenvalidation.NewSessionLifetimeValidator(time.Hour),
+		tokenvalidation.OAuthTokenValidatorFunc(func(token *oauthv1.OAuthAccessToken, _ *userv1.User) error {
+			if token.ClientName == "denied" {
+				return errors.New("client denied")
+			}
+			return nil
+		}),
+	)
+
+	validClaims := func() *Claims {
+		return &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    testIssuer,
+				Subject:   "foo",
+				Audience:  jwt.ClaimStrings{"api"},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+				ID:        "id",
+			},
+			UID:    "bar",
+			Scopes: []string{"user:full"},
+		}
+	}
+	sign := func(claims *Claims, key *ecdsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = keyID
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	for _, test := range []struct {
+		name        string
+		token       func() string
+		expectOK    bool
+		expectError bool
+	}{
+		{
+			name:     "valid",
+			token:    func() string { return sign(validClaims(), signingKey) },
+			expectOK: true,
+		},
+		{
+			name:  "not a JWT",
+			token: func() string { return "sha256~foo" },
+		},
+		{
+			name: "other issuer",
+			token: func() string {
+				claims := validClaims()
+				claims.Issuer = "https://kubernetes.default.svc"
+				return sign(claims, otherKey)
+			},
+		},
+		{
+			name:        "wrong signature",
+			token:       func() string { return sign(validClaims(), otherKey) },
+			expectError: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "lifetime too long",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(now.Add(time.Hour))
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "revoked",
+			token: func() string {
+				claims := validClaims()
+				claims.ID = "revoked"
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "user UID mismatch",
+			token: func() string {
+				claims := validClaims()
+				claims.UID = "bar2"
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "rejected by the validators",
+			token: func() string {
+				claims := validClaims()
+				claims.ClientID = "denied"
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "session within the absolute lifetime",
+			token: func() string {
+				claims := validClaims()
+				claims.AuthTime = jwt.NewNumericDate(now.Add(-30 * time.Minute))
+				return sign(claims, signingKey)
+			},
+			expectOK: true,
+		},
+		{
+			name: "session over the absolute lifetime",
+			token: func() string {
+				claims := validClaims()
+				claims.AuthTime = jwt.NewNumericDate(now.Add(-2 * time.Hour))
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "unknown user",
+			token: func() string {
+				claims := validClaims()
+				claims.Subject = "unknown"
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := validClaims()
+				claims.Audience = jwt.ClaimStrings{"other"}
+				return sign(claims, signingKey)
+			},
+			expectError: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp, ok, err := authenticator.AuthenticateToken(context.TODO(), test.token())
+			if ok != test.expectOK {
+				t.Errorf("expected ok=%v, got %v", test.expectOK, ok)
+			}
+			if (err != nil) != test.expectError {
+				t.Errorf("expected error=%v, got %v", test.expectError, err)
+			}
+			if ok && (resp.User.GetName() != "foo" || resp.User.GetUID() != "bar") {
+				t.Errorf("unexpected user %#v", resp.User)
+			}
+		})
+	}
+}
+
+func TestClaimsAccessToken(t *testing.T) {
+	issued := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "foo",
+			IssuedAt:  jwt.NewNumericDate(issued),
+			ExpiresAt: jwt.NewNumericDate(issued.Add(5 * time.Minute)),
+			ID:        "id",
+		},
+		UID:      "bar",
+		ClientID: "console",
+		AuthTime: jwt.NewNumericDate(issued.Add(-time.Hour)),
+	}
+
+	token := claims.accessToken()
+	if token.UserName != "foo" || token.UserUID != "bar" || token.ClientName != "console" || token.ExpiresIn != 300 ||
+		!token.CreationTimestamp.Time.Equal(issued) || token.InactivityTimeoutSeconds != 0 {
+		t.Errorf("unexpected token %#v", token)
+	}
+	if sessionStart := token.Annotations[oauthapi.SessionStartAnnotation]; sessionStart != "2020-01-02T02:04:05Z" {
+		t.Errorf("expected the session to start at the auth_time, got %q", sessionStart)
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, err := KeyIDFromPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewJWKSHandler([]PublicKey{{KeyID: keyID, Key: &key.PublicKey}}).ServeHTTP(recorder, httptest.NewRequest("GET", JWKSPath, nil))
+
+	keySet := jsonWebKeySet{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &keySet); err != nil {
+		t.Fatal(err)
+	}
+	if len(keySet.Keys) != 1 {
+		t.Fatalf("expected a single key, got %#v", keySet)
+	}
+	if jwk := keySet.Keys[0]; jwk.KeyID != keyID || jwk.KeyType != "EC" || jwk.Curve != "P-256" || jwk.Algorithm != "ES256" || len(jwk.X) != 43 || len(jwk.Y) != 43 {
+		t.Errorf("unexpected key %#v", jwk)
+	}
+}
@@ -0,0 +1,104 @@
+package jwtaccesstoken
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const denyListReloadInterval = 30 * time.Second
+
+// DenyListEntries revokes JWT access tokens before they expire.
+type DenyListEntries struct {
+	// TokenIDs are "jti" claims of revoked tokens
+	TokenIDs []string `json:"tokenIDs,omitempty"`
+	// Users are names of users whose tokens are all revoked
+	Users []string `json:"users,omitempty"`
+	// Clients are names of OAuth clients whose tokens are all revoked
+	Clients []string `json:"clients,omitempty"`
+}
+
+// DenyList holds the revoked JWT access tokens. It is reloaded from a file periodically
+// so that tokens can be revoked without restarting the server.
+// A nil *DenyList does not deny anything.
+type DenyList struct {
+	path string
+
+	lock     sync.RWMutex
+	modTime  time.Time
+	tokenIDs sets.Set[string]
+	users    sets.Set[string]
+	clients  sets.Set[string]
+}
+
+// NewDenyList loads the deny list at path.
+func NewDenyList(path string) (*DenyList, error) {
+	d := &DenyList{path: path}
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Denied is true if the token was revoked.
+func (d *DenyList) Denied(claims *Claims) bool {
+	if d == nil {
+		return false
+	}
+
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.tokenIDs.Has(claims.ID) || d.users.Has(claims.Subject) || d.clients.Has(claims.ClientID)
+}
+
+// Run reloads the deny list whenever its file changes until ctx is done.
+func (d *DenyList) Run(ctx context.Context) {
+	if d == nil {
+		return
+	}
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := d.load(); err != nil {
+			// keep enforcing the last deny list we were able to read
+			klog.Errorf("Failed to reload the JWT access token deny list: %v", err)
+		}
+	}, denyListReloadInterval)
+}
+
+func (d *DenyList) load() error {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.lock.RLock()
+	unchanged := info.ModTime().Equal(d.modTime)
+	d.lock.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+	entries := &DenyListEntries{}
+	if err := yaml.UnmarshalStrict(data, entries); err != nil {
+		return fmt.Errorf("failed to decode deny list %q: %v", d.path, err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.modTime = info.ModTime()
+	d.tokenIDs = sets.New(entries.TokenIDs...)
+	d.users = sets.New(entries.Users...)
+	d.clients = sets.New(entries.Clients...)
+	return nil
+}
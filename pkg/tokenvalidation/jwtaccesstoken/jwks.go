@@ -0,0 +1,126 @@
+package jwtaccesstoken
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+)
+
+const (
+	// DiscoveryPath serves the discovery metadata of the JWT access token issuer.
+	DiscoveryPath = "/.well-known/oauth-access-token-configuration"
+	// JWKSPath serves the public keys that JWT access tokens are verified with.
+	JWKSPath = "/openshift/oauth/v1/jwks"
+)
+
+type discoveryMetadata struct {
+	Issuer               string   `json:"issuer"`
+	JWKSURI              string   `json:"jwks_uri"`
+	SigningAlgsSupported []string `json:"access_token_signing_alg_values_supported"`
+	ClaimsSupported      []string `json:"claims_supported"`
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// ECDSA
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// NewDiscoveryHandler serves the issuer and the location of its JWKS.
+func NewDiscoveryHandler(issuer, jwksURI string) http.Handler {
+	return newJSONHandler(discoveryMetadata{
+		Issuer:               issuer,
+		JWKSURI:              jwksURI,
+		SigningAlgsSupported: validMethods,
+		ClaimsSupported:      []string{"iss", "sub", "aud", "exp", "iat", "nbf", "jti", "uid", "scopes", "client_id", "auth_time"},
+	})
+}
+
+// NewJWKSHandler serves the public keys as a JSON web key set.
+func NewJWKSHandler(keys []PublicKey) http.Handler {
+	keySet := jsonWebKeySet{Keys: []jsonWebKey{}}
+	for _, key := range keys {
+		if jwk, ok := toJSONWebKey(key); ok {
+			keySet.Keys = append(keySet.Keys, jwk)
+		}
+	}
+	return newJSONHandler(keySet)
+}
+
+func newJSONHandler(obj interface{}) http.Handler {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		// all the served types are plain structs of strings
+		panic(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	})
+}
+
+func toJSONWebKey(key PublicKey) (jsonWebKey, bool) {
+	switch k := key.Key.(type) {
+	case *rsa.PublicKey:
+		return jsonWebKey{
+			KeyType:   "RSA",
+			KeyID:     key.KeyID,
+			Use:       "sig",
+			Algorithm: "RS256",
+			N:         encodeBigInt(k.N),
+			E:         encodeBigInt(big.NewInt(int64(k.E))),
+		}, true
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return jsonWebKey{
+			KeyType:   "EC",
+			KeyID:     key.KeyID,
+			Use:       "sig",
+			Algorithm: ecdsaAlgorithm(k),
+			Curve:     k.Curve.Params().Name,
+			X:         base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:         base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}, true
+	default:
+		return jsonWebKey{}, false
+	}
+}
+
+func ecdsaAlgorithm(key *ecdsa.PublicKey) string {
+	switch key.Curve.Params().BitSize {
+	case 384:
+		return "ES384"
+	case 521:
+		return "ES512"
+	default:
+		return "ES256"
+	}
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
@@ -0,0 +1,56 @@
+package jwtaccesstoken
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/client-go/util/keyutil"
+)
+
+// PublicKey is a key that JWT access tokens can be verified with.
+type PublicKey struct {
+	// KeyID is the "kid" header of the tokens signed with the matching private key
+	KeyID string
+	Key   crypto.PublicKey
+}
+
+// ReadPublicKeysFile reads the RSA and ECDSA keys in a PEM file. Private keys are
+// accepted too so that a file holding the signing keys can be used directly.
+func ReadPublicKeysFile(path string) ([]PublicKey, error) {
+	keys, err := keyutil.PublicKeysFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make([]PublicKey, 0, len(keys))
+	for _, key := range keys {
+		keyID, err := KeyIDFromPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public keys from %q: %v", path, err)
+		}
+		publicKeys = append(publicKeys, PublicKey{KeyID: keyID, Key: key})
+	}
+	return publicKeys, nil
+}
+
+// KeyIDFromPublicKey derives the key ID from the SHA-256 hash of the DER encoded public key,
+// the same way the kube-apiserver derives the key IDs of service account token signing keys.
+func KeyIDFromPublicKey(key crypto.PublicKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(h[:]), nil
+}
@@ -1,21 +1,75 @@
 package options
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
-)
 
-const minimumAccessTokenInactivityTimeout = 300
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/oauthaccesstoken"
+	tokenreviews "github.com/openshift/oauth-apiserver/pkg/oauth/apiserver/registry/tokenreviews"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/jwtaccesstoken"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
+)
 
 type TokenValidationOptions struct {
 	AccessTokenInactivityTimeout time.Duration
+	AbsoluteSessionLifetime      time.Duration
 	APIAudiences                 []string
+
+	DisableBootstrapAuthenticator    bool
+	BootstrapUserRotationGracePeriod time.Duration
+
+	JWTAccessTokenIssuer         string
+	JWTAccessTokenJWKSURI        string
+	JWTAccessTokenPublicKeysFile string
+	JWTAccessTokenMaxLifetime    time.Duration
+	JWTAccessTokenDenyListFile   string
+
+	TokenNameHMACKeyFile string
+
+	TokenValidators        []string
+	TokenValidatorSettings map[string]string
+	ShadowTokenValidators  []string
+
+	TokenReviewUserFailureRate    float64
+	TokenReviewUserFailureBurst   int
+	TokenReviewClientFailureRate  float64
+	TokenReviewClientFailureBurst int
+	TokenReviewLockoutDuration    time.Duration
+
+	PersonalAccessTokenMaxLifetime time.Duration
+
+	AccessTokenQuotaPerUser       int
+	AccessTokenQuotaPerUserClient int
+	AccessTokenQuotaPolicy        string
+
+	AccessTokenMaxLifetime      time.Duration
+	AccessTokenLifetimePolicy   string
+	DenyNonExpiringAccessTokens bool
+
+	AccessTokenClientLimitPolicy string
 }
 
 func NewTokenValidationOptions() *TokenValidationOptions {
-	return &TokenValidationOptions{}
+	return &TokenValidationOptions{
+		JWTAccessTokenMaxLifetime:     15 * time.Minute,
+		TokenValidators:               tokenvalidation.DefaultValidatorNames(),
+		TokenReviewUserFailureBurst:   100,
+		TokenReviewClientFailureBurst: 10,
+		TokenReviewLockoutDuration:    time.Minute,
+		// 90 days
+		PersonalAccessTokenMaxLifetime: 90 * 24 * time.Hour,
+		AccessTokenQuotaPolicy:         string(oauthaccesstoken.QuotaPolicyReject),
+		AccessTokenLifetimePolicy:      string(oauthaccesstoken.LifetimePolicyClamp),
+	}
 }
 
 func (o *TokenValidationOptions) AddFlags(fs *pflag.FlagSet) {
@@ -30,28 +84,358 @@ func (o *TokenValidationOptions) AddFlags(fs *pflag.FlagSet) {
 		"\tx = 0  Tokens never time out (default)\n"+
 		"\tx > 0  Tokens time out if there is no activity for x seconds",
 	)
+	fs.DurationVar(&o.AbsoluteSessionLifetime, "absolute-session-lifetime", 0, ""+
+		"defines the maximum amount of time since the user originally authorized a session "+
+		"after which all tokens of that session become invalid. Unlike the token expiration, "+
+		"this limit cannot be extended by getting new tokens through a refresh or a silent "+
+		"re-authorization. An interactive login starts a new session, which the OAuth server signals with the "+
+		oauthapi.InteractiveLoginAnnotation+" annotation on the authorize token. 0 means sessions are not limited (default).")
 	fs.StringSliceVar(&o.APIAudiences, "api-audiences", o.APIAudiences, ""+
 		"Identifiers of the API. The service account token authenticator will validate that "+
 		"tokens used against the API are bound to at least one of these audiences. If the "+
 		"--service-account-issuer flag is configured and this flag is not, this field "+
 		"defaults to a single element list containing the issuer URL.")
+	fs.BoolVar(&o.DisableBootstrapAuthenticator, "disable-bootstrap-authenticator", o.DisableBootstrapAuthenticator, ""+
+		"do not authenticate tokens of the kube:admin bootstrap user at all, even if the kubeadmin secret exists.")
+	fs.DurationVar(&o.BootstrapUserRotationGracePeriod, "bootstrap-user-rotation-grace-period", o.BootstrapUserRotationGracePeriod, ""+
+		"defines how long tokens of the kube:admin bootstrap user that were issued before a change "+
+		"of the kubeadmin secret keep working. The grace period starts at the last write of the secret recorded in its managed fields. "+
+		"The previous secret is only remembered in memory: a server must have seen it to accept its tokens, "+
+		"and after a restart such tokens stop working immediately. "+
+		"0 means such tokens stop working immediately (default).")
+	fs.StringVar(&o.JWTAccessTokenIssuer, "jwt-access-token-issuer", o.JWTAccessTokenIssuer, ""+
+		"Issuer URL of JWT access tokens. If set together with --jwt-access-token-public-keys-file, "+
+		"signed JWT access tokens from this issuer are accepted without any lookup in storage, "+
+		"and the public keys are served at the JWKS endpoint announced by the discovery metadata.")
+	fs.StringVar(&o.JWTAccessTokenJWKSURI, "jwt-access-token-jwks-uri", o.JWTAccessTokenJWKSURI, ""+
+		"URL of the JWKS announced by the discovery metadata of JWT access tokens. Defaults to the path this server "+
+		"serves the public keys at on the issuer URL, which only works if the issuer URL points to this server.")
+	fs.StringVar(&o.JWTAccessTokenPublicKeysFile, "jwt-access-token-public-keys-file", o.JWTAccessTokenPublicKeysFile, ""+
+		"Path to a PEM file, e.g. mounted from a secret, with the RSA or ECDSA keys JWT access tokens are signed with. "+
+		"Private keys are accepted, only their public part is used.")
+	fs.DurationVar(&o.JWTAccessTokenMaxLifetime, "jwt-access-token-max-lifetime", o.JWTAccessTokenMaxLifetime, ""+
+		"JWT access tokens valid for longer than this are rejected. Keep this short, tokens "+
+		"can only be revoked before they expire through the deny list.")
+	fs.StringVar(&o.JWTAccessTokenDenyListFile, "jwt-access-token-deny-list-file", o.JWTAccessTokenDenyListFile, ""+
+		"Path to a YAML or JSON file with the token IDs, users and clients whose JWT access tokens are revoked. "+
+		"The file is reloaded when it changes.")
+	fs.StringVar(&o.TokenNameHMACKeyFile, "token-name-hmac-key-file", o.TokenNameHMACKeyFile, ""+
+		"Path to a file with the secret key of the hmac-sha256~ token hashing scheme. Tokens with this "+
+		"prefix are only accepted if the key is set. The sha256~ and sha512~ schemes are always accepted.")
+	fs.StringSliceVar(&o.TokenValidators, "token-validators", o.TokenValidators, ""+
+		"the validators OAuth access tokens must pass, in the order they run. Leaving out a validator turns its check off, "+
+		"except for "+strings.Join(tokenvalidation.MandatoryValidatorNames(), " and ")+", which are always required. "+
+		"Known validators: "+strings.Join(tokenvalidation.RegisteredValidatorNames(), ", ")+".")
+	fs.StringToStringVar(&o.TokenValidatorSettings, "token-validator-settings", o.TokenValidatorSettings, ""+
+		"settings of the token validators as <validator>.<setting>=<value> pairs, e.g. InactivityTimeout.defaultTimeout=10m. "+
+		"They override the flags the validators default to, e.g. InactivityTimeout.defaultTimeout overrides --accesstoken-inactivity-timeout "+
+		"and must pass the same checks.")
+	fs.StringSliceVar(&o.ShadowTokenValidators, "shadow-token-validators", o.ShadowTokenValidators, ""+
+		"validators of --token-validators to run in shadow mode. They are evaluated but never reject a token, "+
+		"the tokens they would have rejected are reported in metrics, audit annotations and sampled logs instead. "+
+		"Use this to try out a stricter validator before enforcing it.")
+	fs.Float64Var(&o.TokenReviewUserFailureRate, "token-review-user-failure-rate", o.TokenReviewUserFailureRate, ""+
+		"the number of failed token reviews per second each requesting user may sustain across the clients it forwards in the "+
+		tokenreviews.ForwardedClientAnnotation+" annotation. A user exceeding this rate after a burst of --token-review-user-failure-burst "+
+		"failures gets its failed reviews rejected for --token-review-lockout-duration. Successful reviews and reviews without a "+
+		"forwarded client, like those of the kube-apiserver, are never limited. 0 disables the limit (default).")
+	fs.IntVar(&o.TokenReviewUserFailureBurst, "token-review-user-failure-burst", o.TokenReviewUserFailureBurst, ""+
+		"the number of failed token reviews a requesting user may make at once before --token-review-user-failure-rate applies.")
+	fs.Float64Var(&o.TokenReviewClientFailureRate, "token-review-client-failure-rate", o.TokenReviewClientFailureRate, ""+
+		"the number of failed token reviews per second each client identity forwarded by a requesting user in the "+
+		tokenreviews.ForwardedClientAnnotation+" annotation may sustain. 0 disables the limit (default).")
+	fs.IntVar(&o.TokenReviewClientFailureBurst, "token-review-client-failure-burst", o.TokenReviewClientFailureBurst, ""+
+		"the number of failed token reviews a forwarded client may make at once before --token-review-client-failure-rate applies.")
+	fs.DurationVar(&o.TokenReviewLockoutDuration, "token-review-lockout-duration", o.TokenReviewLockoutDuration, ""+
+		"defines how long the failed token reviews of a requesting user or forwarded client that exceeded its failure rate are rejected.")
+	fs.DurationVar(&o.PersonalAccessTokenMaxLifetime, "personal-access-token-max-lifetime", o.PersonalAccessTokenMaxLifetime, ""+
+		"the maximum lifetime of the tokens users create for themselves through useroauthaccesstokenrequests. Longer or "+
+		"non-expiring tokens are capped to it, as well as to the maximum age the session policy sets for the user. "+
+		"0 does not limit the lifetime.")
+	fs.IntVar(&o.AccessTokenQuotaPerUser, "access-token-quota-per-user", o.AccessTokenQuotaPerUser, ""+
+		"the maximum number of live OAuth access tokens of each user. New tokens over the limit are "+
+		"handled according to --access-token-quota-policy. 0 disables the limit (default).")
+	fs.IntVar(&o.AccessTokenQuotaPerUserClient, "access-token-quota-per-user-client", o.AccessTokenQuotaPerUserClient, ""+
+		"the maximum number of live OAuth access tokens of each user for each client. New tokens over the limit are "+
+		"handled according to --access-token-quota-policy. 0 disables the limit (default).")
+	fs.StringVar(&o.AccessTokenQuotaPolicy, "access-token-quota-policy", o.AccessTokenQuotaPolicy, ""+
+		"what happens to new OAuth access tokens over a quota: "+string(oauthaccesstoken.QuotaPolicyReject)+" rejects them, "+
+		string(oauthaccesstoken.QuotaPolicyEvictOldest)+" deletes the oldest tokens of the user, for the same client "+
+		"for the per client limit, to make room for them once they were created.")
+	fs.DurationVar(&o.AccessTokenMaxLifetime, "access-token-max-lifetime", o.AccessTokenMaxLifetime, ""+
+		"the maximum lifetime of all new OAuth access tokens, whichever OAuth server or client creates them. "+
+		"Tokens that never expire exceed it. Longer living tokens are handled according to --access-token-lifetime-policy. "+
+		"0 does not limit the lifetime (default).")
+	fs.StringVar(&o.AccessTokenLifetimePolicy, "access-token-lifetime-policy", o.AccessTokenLifetimePolicy, ""+
+		"what happens to new OAuth access tokens that live longer than --access-token-max-lifetime: "+
+		string(oauthaccesstoken.LifetimePolicyClamp)+" shortens their lifetime to the maximum with a warning, "+
+		string(oauthaccesstoken.LifetimePolicyReject)+" rejects them.")
+	fs.BoolVar(&o.DenyNonExpiringAccessTokens, "deny-non-expiring-access-tokens", o.DenyNonExpiringAccessTokens, ""+
+		"reject new OAuth access tokens that never expire, even if --access-token-max-lifetime is not set.")
+	fs.StringVar(&o.AccessTokenClientLimitPolicy, "access-token-client-limit-policy", o.AccessTokenClientLimitPolicy, ""+
+		"what happens to new OAuth access tokens that live longer or with a later inactivity timeout than the "+
+		"accessTokenMaxAgeSeconds and accessTokenInactivityTimeoutSeconds of their oauthclient allow: "+
+		string(oauthaccesstoken.LifetimePolicyClamp)+" shortens them to the limits of the client with a warning, "+
+		string(oauthaccesstoken.LifetimePolicyReject)+" rejects them. Empty does not enforce the limits of the clients (default).")
 }
 
 func (o *TokenValidationOptions) Validate() []error {
 	errs := []error{}
 
-	errs = append(errs, validateAccessTokenInactivityTimeout(o.AccessTokenInactivityTimeout)...)
+	errs = append(errs, validateAccessTokenInactivityTimeout(o.AccessTokenInactivityTimeout, sessionpolicy.DefaultMinimumInactivityTimeoutSeconds)...)
+	if o.AbsoluteSessionLifetime < 0 {
+		errs = append(errs, fmt.Errorf("absolute-session-lifetime must not be negative"))
+	}
+	if o.BootstrapUserRotationGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("bootstrap-user-rotation-grace-period must not be negative"))
+	}
+	if o.PersonalAccessTokenMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("personal-access-token-max-lifetime must not be negative"))
+	}
+	errs = append(errs, o.validateJWTAccessTokens()...)
+	errs = append(errs, o.validateTokenReviewFailureLimits()...)
+	errs = append(errs, o.validateAccessTokenQuota()...)
+	errs = append(errs, o.validateAccessTokenLifetimeLimit()...)
+	if configs, err := o.TokenValidatorConfigs(); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, tokenvalidation.ValidateValidatorConfigs(configs)...)
+	}
+	errs = append(errs, o.validateInactivityTimeoutSetting(sessionpolicy.DefaultMinimumInactivityTimeoutSeconds)...)
+
+	return errs
+}
+
+// RegisterTokenNameSchemes enables the optional token name hashing schemes.
+func (o *TokenValidationOptions) RegisterTokenNameSchemes() error {
+	if len(o.TokenNameHMACKeyFile) == 0 {
+		return nil
+	}
+
+	key, err := os.ReadFile(o.TokenNameHMACKeyFile)
+	if err != nil {
+		return err
+	}
+	key = bytes.TrimSpace(key)
+	if len(key) < 32 {
+		return fmt.Errorf("token-name-hmac-key-file must contain a key of at least 32 bytes")
+	}
+	tokenname.Register(tokenname.NewHMACSHA256Scheme(key))
+	return nil
+}
+
+// JWTAccessTokenConfig returns the configuration of the JWT access token authenticator,
+// or nil if JWT access tokens are not enabled.
+func (o *TokenValidationOptions) JWTAccessTokenConfig() (*jwtaccesstoken.Config, error) {
+	if len(o.JWTAccessTokenIssuer) == 0 {
+		return nil, nil
+	}
+
+	publicKeys, err := jwtaccesstoken.ReadPublicKeysFile(o.JWTAccessTokenPublicKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &jwtaccesstoken.Config{
+		Issuer:      o.JWTAccessTokenIssuer,
+		JWKSURI:     o.JWTAccessTokenJWKSURI,
+		PublicKeys:  publicKeys,
+		MaxLifetime: o.JWTAccessTokenMaxLifetime,
+	}
+	if len(config.JWKSURI) == 0 {
+		config.JWKSURI = strings.TrimSuffix(o.JWTAccessTokenIssuer, "/") + jwtaccesstoken.JWKSPath
+	}
+	if len(o.JWTAccessTokenDenyListFile) > 0 {
+		config.DenyList, err = jwtaccesstoken.NewDenyList(o.JWTAccessTokenDenyListFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// TokenValidatorConfigs returns the chain of token validators with their settings.
+func (o *TokenValidationOptions) TokenValidatorConfigs() ([]tokenvalidation.ValidatorConfig, error) {
+	configs := make([]tokenvalidation.ValidatorConfig, 0, len(o.TokenValidators))
+	indexes := map[string]int{}
+	for i, name := range o.TokenValidators {
+		configs = append(configs, tokenvalidation.ValidatorConfig{Name: name})
+		indexes[name] = i
+	}
+
+	for _, name := range o.ShadowTokenValidators {
+		i, ok := indexes[name]
+		if !ok {
+			return nil, fmt.Errorf("shadow-token-validators has %q, which is not in token-validators", name)
+		}
+		configs[i].Shadow = true
+	}
+
+	for key, value := range o.TokenValidatorSettings {
+		name, setting, ok := strings.Cut(key, ".")
+		if !ok || len(name) == 0 || len(setting) == 0 {
+			return nil, fmt.Errorf("token-validator-settings key %q must have the form <validator>.<setting>", key)
+		}
+		i, ok := indexes[name]
+		if !ok {
+			return nil, fmt.Errorf("token-validator-settings has settings for %q, which is not in token-validators", name)
+		}
+		if configs[i].Settings == nil {
+			configs[i].Settings = map[string]string{}
+		}
+		configs[i].Settings[setting] = value
+	}
+
+	return configs, nil
+}
+
+// TokenReviewFailureLimits returns the limits of failed token reviews.
+func (o *TokenValidationOptions) TokenReviewFailureLimits() tokenreviews.FailureLimits {
+	return tokenreviews.FailureLimits{
+		UserRate:        o.TokenReviewUserFailureRate,
+		UserBurst:       o.TokenReviewUserFailureBurst,
+		ClientRate:      o.TokenReviewClientFailureRate,
+		ClientBurst:     o.TokenReviewClientFailureBurst,
+		LockoutDuration: o.TokenReviewLockoutDuration,
+	}
+}
+
+func (o *TokenValidationOptions) validateTokenReviewFailureLimits() []error {
+	errs := []error{}
+
+	if o.TokenReviewUserFailureRate < 0 {
+		errs = append(errs, fmt.Errorf("token-review-user-failure-rate must not be negative"))
+	}
+	if o.TokenReviewUserFailureRate > 0 && o.TokenReviewUserFailureBurst < 1 {
+		errs = append(errs, fmt.Errorf("token-review-user-failure-burst must be at least 1"))
+	}
+	if o.TokenReviewClientFailureRate < 0 {
+		errs = append(errs, fmt.Errorf("token-review-client-failure-rate must not be negative"))
+	}
+	if o.TokenReviewClientFailureRate > 0 && o.TokenReviewClientFailureBurst < 1 {
+		errs = append(errs, fmt.Errorf("token-review-client-failure-burst must be at least 1"))
+	}
+	if o.TokenReviewLockoutDuration < 0 {
+		errs = append(errs, fmt.Errorf("token-review-lockout-duration must not be negative"))
+	}
+
+	return errs
+}
+
+// AccessTokenQuota returns the quota of live access tokens.
+func (o *TokenValidationOptions) AccessTokenQuota() oauthaccesstoken.Quota {
+	return oauthaccesstoken.Quota{
+		PerUser:       o.AccessTokenQuotaPerUser,
+		PerUserClient: o.AccessTokenQuotaPerUserClient,
+		Policy:        oauthaccesstoken.QuotaPolicy(o.AccessTokenQuotaPolicy),
+	}
+}
+
+func (o *TokenValidationOptions) validateAccessTokenQuota() []error {
+	errs := []error{}
+
+	if o.AccessTokenQuotaPerUser < 0 {
+		errs = append(errs, fmt.Errorf("access-token-quota-per-user must not be negative"))
+	}
+	if o.AccessTokenQuotaPerUserClient < 0 {
+		errs = append(errs, fmt.Errorf("access-token-quota-per-user-client must not be negative"))
+	}
+	switch oauthaccesstoken.QuotaPolicy(o.AccessTokenQuotaPolicy) {
+	case oauthaccesstoken.QuotaPolicyReject, oauthaccesstoken.QuotaPolicyEvictOldest:
+	default:
+		errs = append(errs, fmt.Errorf("access-token-quota-policy must be %s or %s", oauthaccesstoken.QuotaPolicyReject, oauthaccesstoken.QuotaPolicyEvictOldest))
+	}
+
+	return errs
+}
+
+// AccessTokenLifetimeLimit returns the limit of the lifetime of new access tokens.
+func (o *TokenValidationOptions) AccessTokenLifetimeLimit() oauthaccesstoken.LifetimeLimit {
+	return oauthaccesstoken.LifetimeLimit{
+		MaxLifetime:       o.AccessTokenMaxLifetime,
+		DenyNonExpiring:   o.DenyNonExpiringAccessTokens,
+		Policy:            oauthaccesstoken.LifetimePolicy(o.AccessTokenLifetimePolicy),
+		ClientLimitPolicy: oauthaccesstoken.LifetimePolicy(o.AccessTokenClientLimitPolicy),
+	}
+}
+
+func (o *TokenValidationOptions) validateAccessTokenLifetimeLimit() []error {
+	errs := []error{}
+
+	if o.AccessTokenMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("access-token-max-lifetime must not be negative"))
+	}
+	if o.AccessTokenMaxLifetime > 0 && o.AccessTokenMaxLifetime < time.Second {
+		errs = append(errs, fmt.Errorf("access-token-max-lifetime must be at least 1s"))
+	}
+	switch oauthaccesstoken.LifetimePolicy(o.AccessTokenLifetimePolicy) {
+	case oauthaccesstoken.LifetimePolicyClamp, oauthaccesstoken.LifetimePolicyReject:
+	default:
+		errs = append(errs, fmt.Errorf("access-token-lifetime-policy must be %s or %s", oauthaccesstoken.LifetimePolicyClamp, oauthaccesstoken.LifetimePolicyReject))
+	}
+	switch oauthaccesstoken.LifetimePolicy(o.AccessTokenClientLimitPolicy) {
+	case "", oauthaccesstoken.LifetimePolicyClamp, oauthaccesstoken.LifetimePolicyReject:
+	default:
+		errs = append(errs, fmt.Errorf("access-token-client-limit-policy must be empty, %s or %s", oauthaccesstoken.LifetimePolicyClamp, oauthaccesstoken.LifetimePolicyReject))
+	}
+
+	return errs
+}
+
+func (o *TokenValidationOptions) validateJWTAccessTokens() []error {
+	errs := []error{}
+
+	if len(o.JWTAccessTokenIssuer) == 0 {
+		if len(o.JWTAccessTokenPublicKeysFile) > 0 || len(o.JWTAccessTokenDenyListFile) > 0 || len(o.JWTAccessTokenJWKSURI) > 0 {
+			errs = append(errs, fmt.Errorf("jwt-access-token-issuer is required to accept JWT access tokens"))
+		}
+		return errs
+	}
+
+	if issuer, err := url.Parse(o.JWTAccessTokenIssuer); err != nil || issuer.Scheme != "https" || len(issuer.Host) == 0 {
+		errs = append(errs, fmt.Errorf("jwt-access-token-issuer must be an https URL"))
+	}
+	if len(o.JWTAccessTokenJWKSURI) > 0 {
+		if jwksURI, err := url.Parse(o.JWTAccessTokenJWKSURI); err != nil || jwksURI.Scheme != "https" || len(jwksURI.Host) == 0 {
+			errs = append(errs, fmt.Errorf("jwt-access-token-jwks-uri must be an https URL"))
+		}
+	}
+	if len(o.JWTAccessTokenPublicKeysFile) == 0 {
+		errs = append(errs, fmt.Errorf("jwt-access-token-public-keys-file is required to accept JWT access tokens"))
+	}
+	if o.JWTAccessTokenMaxLifetime <= 0 {
+		errs = append(errs, fmt.Errorf("jwt-access-token-max-lifetime must be greater than 0"))
+	}
+
+	return errs
+}
+
+// validateInactivityTimeoutSetting applies the checks of --accesstoken-inactivity-timeout
+// to the setting of the InactivityTimeout validator that overrides it.
+func (o *TokenValidationOptions) validateInactivityTimeoutSetting(minimumTimeoutSeconds int32) []error {
+	errs := []error{}
+
+	key := tokenvalidation.InactivityTimeoutValidatorName + ".defaultTimeout"
+	value, ok := o.TokenValidatorSettings[key]
+	if !ok {
+		return errs
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return append(errs, fmt.Errorf("token-validator-settings %s is invalid: %v", key, err))
+	}
+	if !tokenvalidation.ValidInactivityTimeout(timeout, minimumTimeoutSeconds) {
+		errs = append(errs, fmt.Errorf("token-validator-settings %s must either be 0 or greater than %d", key, minimumTimeoutSeconds))
+	}
 
 	return errs
 }
 
-func validateAccessTokenInactivityTimeout(timeout time.Duration) []error {
+func validateAccessTokenInactivityTimeout(timeout time.Duration, minimumTimeoutSeconds int32) []error {
 	errs := []error{}
 
-	// int32 will always round down to units, but that's ok
-	timeoutSeconds := int32(timeout.Seconds())
-	if timeoutSeconds < 0 || (timeoutSeconds > 0 && timeoutSeconds < minimumAccessTokenInactivityTimeout) {
-		errs = append(errs, fmt.Errorf("accesstoken-inactivity-timeout must either be 0 or greater than %d", minimumAccessTokenInactivityTimeout))
+	if !tokenvalidation.ValidInactivityTimeout(timeout, minimumTimeoutSeconds) {
+		errs = append(errs, fmt.Errorf("accesstoken-inactivity-timeout must either be 0 or greater than %d", minimumTimeoutSeconds))
 	}
 
 	return errs
@@ -0,0 +1,234 @@
+package tokenvalidation
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	oauthclientlister "github.com/openshift/client-go/oauth/listers/oauth/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
+)
+
+// The names of the built-in validators.
+const (
+	ExpirationValidatorName        = "Expiration"
+	MaxAgeValidatorName            = "MaxAge"
+	SessionLifetimeValidatorName   = "SessionLifetime"
+	UserUIDValidatorName           = "UserUID"
+	InactivityTimeoutValidatorName = "InactivityTimeout"
+)
+
+// ValidatorDependencies are the server wide settings and clients validators are built with.
+type ValidatorDependencies struct {
+	Tokens                       oauthclient.OAuthAccessTokenInterface
+	OAuthClients                 oauthclientlister.OAuthClientLister
+	SessionPolicyEvaluator       *SessionPolicyEvaluator
+	AccessTokenInactivityTimeout time.Duration
+	AbsoluteSessionLifetime      time.Duration
+}
+
+// ValidatorRegistration describes how to build a validator.
+type ValidatorRegistration struct {
+	// Settings are the names of the settings the validator accepts
+	Settings []string
+	// Mandatory validators must be in every chain and cannot run in shadow mode
+	Mandatory bool
+	// New builds the validator. settings only holds keys from Settings.
+	New func(deps ValidatorDependencies, settings map[string]string) (OAuthTokenValidator, error)
+}
+
+// ValidatorConfig selects a validator of the chain and its settings.
+type ValidatorConfig struct {
+	Name     string
+	Settings map[string]string
+	// Shadow runs the validator in shadow mode, see NewShadowValidator
+	Shadow bool
+}
+
+// ValidatorRunner is implemented by validators with background work, which is started
+// with the server and stopped when stopCh is closed.
+type ValidatorRunner interface {
+	Run(stopCh <-chan struct{})
+}
+
+var (
+	validatorsLock sync.RWMutex
+	validators     = map[string]ValidatorRegistration{}
+)
+
+func init() {
+	RegisterValidator(ExpirationValidatorName, ValidatorRegistration{
+		Mandatory: true,
+		New: func(ValidatorDependencies, map[string]string) (OAuthTokenValidator, error) {
+			return NewExpirationValidator(), nil
+		},
+	})
+	RegisterValidator(MaxAgeValidatorName, ValidatorRegistration{
+		New: func(deps ValidatorDependencies, _ map[string]string) (OAuthTokenValidator, error) {
+			return NewMaxAgeValidator(deps.SessionPolicyEvaluator), nil
+		},
+	})
+	// the lifetime is only set by --absolute-session-lifetime, which new tokens continue
+	// their sessions within as well
+	RegisterValidator(SessionLifetimeValidatorName, ValidatorRegistration{
+		New: func(deps ValidatorDependencies, _ map[string]string) (OAuthTokenValidator, error) {
+			return NewSessionLifetimeValidator(deps.AbsoluteSessionLifetime), nil
+		},
+	})
+	RegisterValidator(UserUIDValidatorName, ValidatorRegistration{
+		Mandatory: true,
+		New: func(ValidatorDependencies, map[string]string) (OAuthTokenValidator, error) {
+			return NewUIDValidator(), nil
+		},
+	})
+	RegisterValidator(InactivityTimeoutValidatorName, ValidatorRegistration{
+		Settings: []string{"defaultTimeout"},
+		New: func(deps ValidatorDependencies, settings map[string]string) (OAuthTokenValidator, error) {
+			defaultTimeout, err := durationSetting(settings, "defaultTimeout", deps.AccessTokenInactivityTimeout)
+			if err != nil {
+				return nil, err
+			}
+			// the session policy can only raise the minimum, which the validator applies to every token
+			minimumTimeoutSeconds := int32(sessionpolicy.DefaultMinimumInactivityTimeoutSeconds)
+			if !ValidInactivityTimeout(defaultTimeout, minimumTimeoutSeconds) {
+				return nil, fmt.Errorf("defaultTimeout must either be 0 or greater than %d", minimumTimeoutSeconds)
+			}
+			return NewTimeoutValidator(deps.Tokens, deps.OAuthClients, defaultTimeout, minimumTimeoutSeconds, deps.SessionPolicyEvaluator), nil
+		},
+	})
+}
+
+// DefaultValidatorNames returns the validators that run if no chain is configured, in order.
+func DefaultValidatorNames() []string {
+	return []string{ExpirationValidatorName, MaxAgeValidatorName, SessionLifetimeValidatorName, UserUIDValidatorName, InactivityTimeoutValidatorName}
+}
+
+// MandatoryValidatorNames returns the names of the validators every chain must enforce, sorted.
+func MandatoryValidatorNames() []string {
+	validatorsLock.RLock()
+	defer validatorsLock.RUnlock()
+	return mandatoryValidatorNames()
+}
+
+func mandatoryValidatorNames() []string {
+	names := []string{}
+	for _, name := range registeredValidatorNames() {
+		if validators[name].Mandatory {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RegisterValidator makes a validator available by name. Registering a name twice replaces
+// the earlier registration.
+func RegisterValidator(name string, registration ValidatorRegistration) {
+	validatorsLock.Lock()
+	defer validatorsLock.Unlock()
+	validators[name] = registration
+}
+
+// RegisteredValidatorNames returns the names of all registered validators, sorted.
+func RegisteredValidatorNames() []string {
+	validatorsLock.RLock()
+	defer validatorsLock.RUnlock()
+	return registeredValidatorNames()
+}
+
+func registeredValidatorNames() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateValidatorConfigs checks that the chain only holds registered validators,
+// each at most once, with settings they accept, and that it enforces the mandatory ones.
+func ValidateValidatorConfigs(configs []ValidatorConfig) []error {
+	validatorsLock.RLock()
+	defer validatorsLock.RUnlock()
+
+	errs := []error{}
+	seen := map[string]bool{}
+	for _, config := range configs {
+		registration, ok := validators[config.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown token validator %q, known validators are %q", config.Name, registeredValidatorNames()))
+			continue
+		}
+		if seen[config.Name] {
+			errs = append(errs, fmt.Errorf("token validator %q must not be used more than once", config.Name))
+		}
+		seen[config.Name] = true
+		if registration.Mandatory && config.Shadow {
+			errs = append(errs, fmt.Errorf("token validator %q is mandatory and must not run in shadow mode", config.Name))
+		}
+		for key := range config.Settings {
+			if !slices.Contains(registration.Settings, key) {
+				errs = append(errs, fmt.Errorf("token validator %q has no setting %q, its settings are %q", config.Name, key, registration.Settings))
+			}
+		}
+	}
+	for _, name := range mandatoryValidatorNames() {
+		if !seen[name] {
+			errs = append(errs, fmt.Errorf("token validator %q is mandatory and must not be left out", name))
+		}
+	}
+	return errs
+}
+
+// Enforces returns whether the chain enforces the named validator, i.e. it is part of the chain
+// and does not run in shadow mode.
+func Enforces(configs []ValidatorConfig, name string) bool {
+	for _, config := range configs {
+		if config.Name == name {
+			return !config.Shadow
+		}
+	}
+	return false
+}
+
+// NewValidatorChain builds the validators of the chain in order. Every validator is
+// named for token diagnosis, validators in shadow mode are wrapped by NewShadowValidator.
+func NewValidatorChain(configs []ValidatorConfig, deps ValidatorDependencies) ([]OAuthTokenValidator, error) {
+	if errs := ValidateValidatorConfigs(configs); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	validatorsLock.RLock()
+	defer validatorsLock.RUnlock()
+
+	chain := make([]OAuthTokenValidator, 0, len(configs))
+	for _, config := range configs {
+		validator, err := validators[config.Name].New(deps, config.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token validator %q: %v", config.Name, err)
+		}
+		if config.Shadow {
+			validator = NewShadowValidator(config.Name, validator)
+		}
+		chain = append(chain, NamedValidator(config.Name, validator))
+	}
+	return chain, nil
+}
+
+func durationSetting(settings map[string]string, key string, defaultValue time.Duration) (time.Duration, error) {
+	value, ok := settings[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("%s must not be negative", key)
+	}
+	return duration, nil
+}
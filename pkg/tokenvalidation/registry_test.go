@@ -0,0 +1,114 @@
+package tokenvalidation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+)
+
+func TestValidateValidatorConfigs(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		configs     []ValidatorConfig
+		expectedErr bool
+	}{
+		{name: "default chain", configs: defaultConfigs()},
+		{name: "reordered with settings", configs: []ValidatorConfig{{Name: UserUIDValidatorName}, {Name: InactivityTimeoutValidatorName, Settings: map[string]string{"defaultTimeout": "10m"}}, {Name: ExpirationValidatorName}}},
+		{name: "empty chain", configs: []ValidatorConfig{}, expectedErr: true},
+		{name: "mandatory validator left out", configs: []ValidatorConfig{{Name: UserUIDValidatorName}}, expectedErr: true},
+		{name: "mandatory validator in shadow mode", configs: []ValidatorConfig{{Name: ExpirationValidatorName}, {Name: UserUIDValidatorName, Shadow: true}}, expectedErr: true},
+		{name: "session lifetime setting", configs: []ValidatorConfig{{Name: ExpirationValidatorName}, {Name: UserUIDValidatorName}, {Name: SessionLifetimeValidatorName, Settings: map[string]string{"lifetime": "24h"}}}, expectedErr: true},
+		{name: "unknown validator", configs: []ValidatorConfig{{Name: "Unknown"}}, expectedErr: true},
+		{name: "duplicate validator", configs: []ValidatorConfig{{Name: UserUIDValidatorName}, {Name: UserUIDValidatorName}}, expectedErr: true},
+		{name: "unknown setting", configs: []ValidatorConfig{{Name: UserUIDValidatorName, Settings: map[string]string{"strict": "true"}}}, expectedErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if errs := ValidateValidatorConfigs(test.configs); (len(errs) > 0) != test.expectedErr {
+				t.Errorf("expected error %v, got %v", test.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestEnforces(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		configs  []ValidatorConfig
+		expected bool
+	}{
+		{name: "default chain", configs: defaultConfigs(), expected: true},
+		{name: "left out", configs: []ValidatorConfig{{Name: ExpirationValidatorName}, {Name: UserUIDValidatorName}}},
+		{name: "shadow mode", configs: []ValidatorConfig{{Name: ExpirationValidatorName}, {Name: InactivityTimeoutValidatorName, Shadow: true}}},
+	} {
+		if got := Enforces(test.configs, InactivityTimeoutValidatorName); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestNewValidatorChain(t *testing.T) {
+	errRejected := errors.New("rejected by the experimental validator")
+	RegisterValidator("Experimental", ValidatorRegistration{
+		Settings: []string{"reject"},
+		New: func(_ ValidatorDependencies, settings map[string]string) (OAuthTokenValidator, error) {
+			return OAuthTokenValidatorFunc(func(*oauthv1.OAuthAccessToken, *userv1.User) error {
+				if settings["reject"] == "true" {
+					return errRejected
+				}
+				return nil
+			}), nil
+		},
+	})
+
+	chain, err := NewValidatorChain([]ValidatorConfig{
+		{Name: "Experimental", Settings: map[string]string{"reject": "true"}},
+		{Name: SessionLifetimeValidatorName},
+		{Name: ExpirationValidatorName},
+		{Name: UserUIDValidatorName},
+	}, ValidatorDependencies{AbsoluteSessionLifetime: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, validator := range chain {
+		names = append(names, validator.(NamedOAuthTokenValidator).Name)
+	}
+	if expected := []string{"Experimental", SessionLifetimeValidatorName, ExpirationValidatorName, UserUIDValidatorName}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the validators %v, got %v", expected, names)
+	}
+
+	token := &oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))}}
+	if err := chain[0].Validate(token, &userv1.User{}); err != errRejected {
+		t.Errorf("expected the experimental validator to reject the token, got %v", err)
+	}
+	if err := chain[1].Validate(token, &userv1.User{}); err != errSessionExpired {
+		t.Errorf("expected the absolute session lifetime to apply, got %v", err)
+	}
+
+	mandatory := []ValidatorConfig{{Name: ExpirationValidatorName}, {Name: UserUIDValidatorName}}
+	for _, settings := range []map[string]string{{"defaultTimeout": "forever"}, {"defaultTimeout": "1m"}} {
+		configs := append([]ValidatorConfig{{Name: InactivityTimeoutValidatorName, Settings: settings}}, mandatory...)
+		if _, err := NewValidatorChain(configs, ValidatorDependencies{}); err == nil {
+			t.Errorf("expected the invalid setting %v to fail", settings)
+		}
+	}
+	configs := append([]ValidatorConfig{{Name: InactivityTimeoutValidatorName, Settings: map[string]string{"defaultTimeout": "0"}}}, mandatory...)
+	if _, err := NewValidatorChain(configs, ValidatorDependencies{}); err != nil {
+		t.Errorf("expected a default timeout of 0 to disable the timeout, got %v", err)
+	}
+}
+
+func defaultConfigs() []ValidatorConfig {
+	var configs []ValidatorConfig
+	for _, name := range DefaultValidatorNames() {
+		configs = append(configs, ValidatorConfig{Name: name})
+	}
+	return configs
+}
@@ -0,0 +1,73 @@
+package tokenvalidation
+
+import (
+	"strings"
+	"time"
+
+	userv1 "github.com/openshift/api/user/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
+)
+
+// SessionPolicyEvaluator resolves the session policy limits that apply to a user.
+// A nil *SessionPolicyEvaluator never limits anything.
+type SessionPolicyEvaluator struct {
+	policy      sessionpolicy.Getter
+	groupMapper UserToGroupMapper
+}
+
+func NewSessionPolicyEvaluator(policy sessionpolicy.Getter, groupMapper UserToGroupMapper) *SessionPolicyEvaluator {
+	return &SessionPolicyEvaluator{
+		policy:      policy,
+		groupMapper: groupMapper,
+	}
+}
+
+// LimitsFor returns the strictest limits of all the policy rules matching the user.
+func (e *SessionPolicyEvaluator) LimitsFor(user *userv1.User) (sessionpolicy.Limits, error) {
+	policy := e.currentPolicy()
+	if !policy.HasRules() {
+		return sessionpolicy.Limits{}, nil
+	}
+
+	groups, err := e.groupMapper.GroupsFor(user.Name)
+	if err != nil {
+		return sessionpolicy.Limits{}, err
+	}
+	groupNames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name)
+	}
+
+	return policy.LimitsFor(identityProviders(user), groupNames), nil
+}
+
+// MinimumInactivityTimeout returns the shortest inactivity timeout any token gets,
+// or 0 for a nil *SessionPolicyEvaluator.
+func (e *SessionPolicyEvaluator) MinimumInactivityTimeout() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.currentPolicy().MinimumInactivityTimeout()
+}
+
+func (e *SessionPolicyEvaluator) currentPolicy() *sessionpolicy.SessionPolicy {
+	if e == nil || e.policy == nil {
+		return sessionpolicy.NewDefaultSessionPolicy()
+	}
+	return e.policy.Get()
+}
+
+// identityProviders returns the names of the identity providers of the user's identities.
+// Identity names have the form <providerName>:<providerUserName>.
+func identityProviders(user *userv1.User) []string {
+	providers := make([]string, 0, len(user.Identities))
+	for _, identity := range user.Identities {
+		provider, _, found := strings.Cut(identity, ":")
+		if !found {
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -0,0 +1,76 @@
+package sessionpolicy
+
+import (
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+)
+
+// Getter returns the session policy currently in effect.
+type Getter interface {
+	Get() *SessionPolicy
+}
+
+type static struct {
+	policy *SessionPolicy
+}
+
+// Static returns a Getter that always returns policy.
+func Static(policy *SessionPolicy) Getter {
+	return static{policy: policy}
+}
+
+func (s static) Get() *SessionPolicy {
+	return s.policy
+}
+
+// Informer is a Getter that follows the cluster session policy. Until it synced, and
+// while the cluster has no session policy, the default policy is in effect.
+type Informer struct {
+	informer cache.SharedIndexInformer
+	policy   atomic.Pointer[SessionPolicy]
+}
+
+// NewInformer watches session policies with lw. Validation only admits the policy
+// named "cluster", so every policy it sees is the cluster policy.
+func NewInformer(lw cache.ListerWatcher, resyncPeriod time.Duration) (*Informer, error) {
+	i := &Informer{
+		informer: cache.NewSharedIndexInformer(lw, &sessionpolicyv1.SessionPolicy{}, resyncPeriod, cache.Indexers{}),
+	}
+	i.policy.Store(NewDefaultSessionPolicy())
+
+	if _, err := i.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    i.set,
+		UpdateFunc: func(_, obj interface{}) { i.set(obj) },
+		DeleteFunc: func(interface{}) { i.policy.Store(NewDefaultSessionPolicy()) },
+	}); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *Informer) set(obj interface{}) {
+	policy, ok := obj.(*sessionpolicyv1.SessionPolicy)
+	if !ok {
+		return
+	}
+	i.policy.Store(FromAPI(policy))
+}
+
+// Get returns the cluster session policy.
+func (i *Informer) Get() *SessionPolicy {
+	return i.policy.Load()
+}
+
+// Run watches the session policies until stopCh is closed.
+func (i *Informer) Run(stopCh <-chan struct{}) {
+	i.informer.Run(stopCh)
+}
+
+// HasSynced returns true once the cluster session policy was listed.
+func (i *Informer) HasSynced() bool {
+	return i.informer.HasSynced()
+}
@@ -0,0 +1,66 @@
+package sessionpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+)
+
+func TestInformer(t *testing.T) {
+	policy := &sessionpolicyv1.SessionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: sessionpolicyv1.ClusterSessionPolicyName, ResourceVersion: "1"},
+		Spec: sessionpolicyv1.SessionPolicySpec{
+			MinimumInactivityTimeoutSeconds: 600,
+			Rules: []sessionpolicyv1.SessionPolicyRule{
+				{Name: "admins", Groups: []string{"admins"}, InactivityTimeoutSeconds: 900},
+			},
+		},
+	}
+	watcher := watch.NewFake()
+	lw := &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &sessionpolicyv1.SessionPolicyList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}, Items: []sessionpolicyv1.SessionPolicy{*policy}}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watcher, nil
+		},
+	}
+
+	informer, err := NewInformer(lw, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := informer.Get(); got.HasRules() || got.MinimumInactivityTimeoutSeconds != DefaultMinimumInactivityTimeoutSeconds {
+		t.Fatalf("expected the default policy before the informer synced, got %+v", got)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+
+	got := informer.Get()
+	if got.MinimumInactivityTimeout() != 10*time.Minute {
+		t.Errorf("expected the minimum inactivity timeout of the cluster policy, got %v", got.MinimumInactivityTimeout())
+	}
+	if limits := got.LimitsFor(nil, []string{"admins"}); limits.InactivityTimeout != 15*time.Minute {
+		t.Errorf("expected the rules of the cluster policy, got %+v", limits)
+	}
+
+	watcher.Delete(policy)
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		return !informer.Get().HasRules(), nil
+	}); err != nil {
+		t.Errorf("expected the default policy after the cluster policy was deleted: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package sessionpolicy
+
+import (
+	"time"
+
+	sessionpolicyv1 "github.com/openshift/oauth-apiserver/pkg/oauth/apis/sessionpolicy/v1"
+)
+
+// DefaultMinimumInactivityTimeoutSeconds is the smallest inactivity timeout
+// allowed when a policy does not set one explicitly.
+const DefaultMinimumInactivityTimeoutSeconds = 300
+
+// SessionPolicy defines cluster-wide overrides for the lifetime of OAuth access tokens.
+// The overrides are selected by the groups of the token's user or by the identity
+// providers the user logged in with. It is the evaluated form of the served
+// sessionpolicies.oauth.openshift.io resource, see FromAPI.
+type SessionPolicy struct {
+	// MinimumInactivityTimeoutSeconds is the shortest inactivity timeout any token gets,
+	// shorter timeouts of the client or of a rule of this policy are raised to it.
+	// Defaults to DefaultMinimumInactivityTimeoutSeconds.
+	MinimumInactivityTimeoutSeconds int32
+
+	// Rules are the overrides. When several rules match a user, the strictest
+	// value of each limit is used.
+	Rules []Rule
+}
+
+// Rule selects users by group or identity provider and limits the lifetime of their tokens.
+// A rule matches a user that is a member of any of Groups or has an identity from any of
+// IdentityProviders.
+type Rule struct {
+	// Name identifies the rule in logs.
+	Name string
+
+	Groups            []string
+	IdentityProviders []string
+
+	// InactivityTimeoutSeconds is the maximum amount of time that can pass between
+	// consecutive uses of a token. 0 or unset means the rule does not limit inactivity.
+	InactivityTimeoutSeconds int32
+	// MaxAgeSeconds is the maximum age of a token, regardless of its expiration.
+	// 0 or unset means the rule does not limit the age of tokens.
+	MaxAgeSeconds int64
+}
+
+// Limits are the effective session limits for a single user. A zero value means no limit.
+type Limits struct {
+	InactivityTimeout time.Duration
+	MaxAge            time.Duration
+}
+
+// NewDefaultSessionPolicy returns a policy without any rules.
+func NewDefaultSessionPolicy() *SessionPolicy {
+	return &SessionPolicy{
+		MinimumInactivityTimeoutSeconds: DefaultMinimumInactivityTimeoutSeconds,
+	}
+}
+
+// FromAPI returns the policy a served, validated session policy defines.
+func FromAPI(policy *sessionpolicyv1.SessionPolicy) *SessionPolicy {
+	p := NewDefaultSessionPolicy()
+	if policy.Spec.MinimumInactivityTimeoutSeconds > 0 {
+		p.MinimumInactivityTimeoutSeconds = policy.Spec.MinimumInactivityTimeoutSeconds
+	}
+	for _, rule := range policy.Spec.Rules {
+		p.Rules = append(p.Rules, Rule{
+			Name:                     rule.Name,
+			Groups:                   rule.Groups,
+			IdentityProviders:        rule.IdentityProviders,
+			InactivityTimeoutSeconds: rule.InactivityTimeoutSeconds,
+			MaxAgeSeconds:            rule.MaxAgeSeconds,
+		})
+	}
+	return p
+}
+
+// LimitsFor returns the strictest limits of all the rules that match a user
+// with the given identity providers and groups.
+func (p *SessionPolicy) LimitsFor(identityProviders, groups []string) Limits {
+	limits := Limits{}
+	for _, rule := range p.Rules {
+		if !rule.matches(identityProviders, groups) {
+			continue
+		}
+		limits.InactivityTimeout = Strictest(limits.InactivityTimeout, time.Duration(rule.InactivityTimeoutSeconds)*time.Second)
+		limits.MaxAge = Strictest(limits.MaxAge, time.Duration(rule.MaxAgeSeconds)*time.Second)
+	}
+	return limits
+}
+
+// HasRules is true if the policy can override any limits at all.
+func (p *SessionPolicy) HasRules() bool {
+	return len(p.Rules) > 0
+}
+
+// MinimumInactivityTimeout returns the shortest inactivity timeout any token gets.
+func (p *SessionPolicy) MinimumInactivityTimeout() time.Duration {
+	return time.Duration(p.MinimumInactivityTimeoutSeconds) * time.Second
+}
+
+func (r *Rule) matches(identityProviders, groups []string) bool {
+	return containsAny(r.IdentityProviders, identityProviders) || containsAny(r.Groups, groups)
+}
+
+func containsAny(selectors, values []string) bool {
+	for _, s := range selectors {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Strictest returns the smallest positive duration. Zero durations mean
+// "no limit" and are only returned if all durations are zero.
+func Strictest(durations ...time.Duration) time.Duration {
+	var strictest time.Duration
+	for _, d := range durations {
+		if d > 0 && (strictest == 0 || d < strictest) {
+			strictest = d
+		}
+	}
+	return strictest
+}
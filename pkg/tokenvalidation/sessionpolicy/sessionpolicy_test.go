@@ -0,0 +1,66 @@
+package sessionpolicy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitsFor(t *testing.T) {
+	policy := &SessionPolicy{
+		MinimumInactivityTimeoutSeconds: DefaultMinimumInactivityTimeoutSeconds,
+		Rules: []Rule{
+			{Name: "admins", Groups: []string{"admins"}, InactivityTimeoutSeconds: 900, MaxAgeSeconds: 8 * 3600},
+			{Name: "developers", Groups: []string{"developers"}, InactivityTimeoutSeconds: 3600},
+			{Name: "break-glass", IdentityProviders: []string{"break-glass"}, MaxAgeSeconds: 600},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		identityProviders []string
+		groups            []string
+		want              Limits
+	}{
+		{
+			name: "no match",
+			want: Limits{},
+		},
+		{
+			name:   "single group",
+			groups: []string{"developers"},
+			want:   Limits{InactivityTimeout: time.Hour},
+		},
+		{
+			name:   "strictest of several groups",
+			groups: []string{"developers", "admins"},
+			want:   Limits{InactivityTimeout: 15 * time.Minute, MaxAge: 8 * time.Hour},
+		},
+		{
+			name:              "identity provider",
+			identityProviders: []string{"break-glass"},
+			groups:            []string{"developers"},
+			want:              Limits{InactivityTimeout: time.Hour, MaxAge: 10 * time.Minute},
+		},
+		{
+			name:              "other identity provider",
+			identityProviders: []string{"ldap"},
+			want:              Limits{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.LimitsFor(tt.identityProviders, tt.groups); got != tt.want {
+				t.Errorf("LimitsFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictest(t *testing.T) {
+	if got := Strictest(0, 0); got != 0 {
+		t.Errorf("expected no limit, got %v", got)
+	}
+	if got := Strictest(0, time.Minute, time.Hour); got != time.Minute {
+		t.Errorf("expected a minute, got %v", got)
+	}
+}
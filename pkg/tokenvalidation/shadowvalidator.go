@@ -0,0 +1,87 @@
+package tokenvalidation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+)
+
+// ShadowDenialAnnotationPrefix prefixes the audit annotation with the would-be denial
+// of a validator in shadow mode. The lower-cased name of the validator completes the key.
+const ShadowDenialAnnotationPrefix = "authentication.openshift.io/shadow-denial-"
+
+var shadowDenials = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Name:           "openshift_oauth_apiserver_token_validator_shadow_denials_total",
+		Help:           "Number of tokens a token validator in shadow mode would have rejected, by validator.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"validator"},
+)
+
+func init() {
+	legacyregistry.MustRegister(shadowDenials)
+}
+
+type shadowValidator struct {
+	name      string
+	validator OAuthTokenValidator
+	// logs are sampled, a validator in shadow mode may reject a lot of tokens
+	logSampler *rate.Sometimes
+}
+
+// NewShadowValidator runs validator in shadow mode: it is evaluated but never rejects
+// a token. Would-be rejections are recorded as metrics, audit annotations and sampled logs.
+func NewShadowValidator(name string, validator OAuthTokenValidator) OAuthTokenValidator {
+	shadow := &shadowValidator{
+		name:       name,
+		validator:  validator,
+		logSampler: &rate.Sometimes{First: 10, Interval: time.Minute},
+	}
+	// the background work of the validator still needs to run
+	if runner, ok := validator.(ValidatorRunner); ok {
+		return &shadowRunnerValidator{shadowValidator: shadow, ValidatorRunner: runner}
+	}
+	return shadow
+}
+
+type shadowRunnerValidator struct {
+	*shadowValidator
+	ValidatorRunner
+}
+
+func (v *shadowValidator) Validate(token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	return v.ValidateWithContext(context.Background(), token, user)
+}
+
+func (v *shadowValidator) ValidateWithContext(ctx context.Context, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	err := ValidateWithContext(ctx, v.validator, token, user)
+	if err == nil {
+		return nil
+	}
+
+	shadowDenials.WithLabelValues(v.name).Inc()
+	denial := fmt.Sprintf("user=%q client=%q reason=%q", token.UserName, token.ClientName, err.Error())
+	audit.AddAuditAnnotation(ctx, ShadowDenialAnnotationPrefix+strings.ToLower(v.name), denial)
+	v.logSampler.Do(func() {
+		klog.Infof("Token validator %s in shadow mode would have rejected a token: %s", v.name, denial)
+	})
+	return nil
+}
+
+// ValidateOnly never rejects anything and records nothing, diagnosing a token must not
+// show up as a would-be denial
+func (v *shadowValidator) ValidateOnly(*oauthv1.OAuthAccessToken, *userv1.User) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package tokenvalidation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/component-base/metrics/testutil"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+)
+
+func TestShadowValidator(t *testing.T) {
+	strict := NewShadowValidator("Strict", OAuthTokenValidatorFunc(func(token *oauthv1.OAuthAccessToken, _ *userv1.User) error {
+		if token.ClientName == "legacy" {
+			return errors.New("client is not allowed")
+		}
+		return nil
+	}))
+	before, err := testutil.GetCounterMetricValue(shadowDenials.WithLabelValues("Strict"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := audit.WithAuditContext(context.Background())
+	if err := ValidateWithContext(ctx, OAuthTokenValidators{strict}, &oauthv1.OAuthAccessToken{UserName: "foo", ClientName: "legacy"}, &userv1.User{}); err != nil {
+		t.Fatalf("a validator in shadow mode must not reject tokens, got %v", err)
+	}
+	annotation, ok := audit.AuditContextFrom(ctx).GetEventAnnotation(ShadowDenialAnnotationPrefix + "strict")
+	if expected := `user="foo" client="legacy" reason="client is not allowed"`; !ok || annotation != expected {
+		t.Errorf("expected the audit annotation %q, got %q", expected, annotation)
+	}
+
+	ctx = audit.WithAuditContext(context.Background())
+	if err := strict.(ContextOAuthTokenValidator).ValidateWithContext(ctx, &oauthv1.OAuthAccessToken{UserName: "foo", ClientName: "console"}, &userv1.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := audit.AuditContextFrom(ctx).GetEventAnnotation(ShadowDenialAnnotationPrefix + "strict"); ok {
+		t.Error("unexpected audit annotation for a valid token")
+	}
+
+	after, err := testutil.GetCounterMetricValue(shadowDenials.WithLabelValues("Strict"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after-before != 1 {
+		t.Errorf("expected a single would-be denial to be counted, got %v", after-before)
+	}
+}
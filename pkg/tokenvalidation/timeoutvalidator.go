@@ -5,6 +5,9 @@ import (
 	"errors"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 
@@ -17,35 +20,74 @@ import (
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	oauthclientlister "github.com/openshift/client-go/oauth/listers/oauth/v1"
 
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
 	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/rankedset"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
 )
 
 var errTimedout = errors.New("token timed out")
 
+// lastUsedUpdatePeriod is how old the recorded last use of a token must be before it is
+// updated, it keeps the writes for tokens in use rare
+const lastUsedUpdatePeriod = 5 * time.Minute
+
 // Implements rankedset.Item
 var _ = rankedset.Item(&tokenData{})
 
 type tokenData struct {
 	token *oauthv1.OAuthAccessToken
+	user  *userv1.User
 	seen  time.Time
+	// updateTimeout is false for tokens that only need their last use recorded
+	updateTimeout bool
+	// requestSpan is the span of the request the token was seen in, the update is linked to it
+	requestSpan trace.Span
 }
 
 func (a *tokenData) timeout() time.Time {
 	return a.token.CreationTimestamp.Time.Add(time.Duration(a.token.InactivityTimeoutSeconds) * time.Second)
 }
 
+// flushBy is the time the token needs to be updated by. Recording the last use is
+// not urgent, it is done with the next regular flush.
+func (a *tokenData) flushBy() time.Time {
+	if !a.updateTimeout {
+		return a.seen
+	}
+	return a.timeout()
+}
+
 func (a *tokenData) Key() string {
 	return a.token.Name
 }
 
 func (a *tokenData) Rank() int64 {
-	return a.timeout().Unix()
+	return a.flushBy().Unix()
+}
+
+// lastUsed returns the last use of the token to record, or false if the recorded one is recent enough
+func (a *tokenData) lastUsed(token *oauthv1.OAuthAccessToken) (time.Time, bool) {
+	lastUsed := a.seen.UTC().Truncate(time.Minute)
+	if value, ok := token.Annotations[oauthapi.LastUsedAnnotation]; ok {
+		if recorded, err := time.Parse(time.RFC3339, value); err == nil && lastUsed.Sub(recorded) < lastUsedUpdatePeriod {
+			return time.Time{}, false
+		}
+	}
+	return lastUsed, true
 }
 
 func timeoutAsDuration(timeout int32) time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// ValidInactivityTimeout returns whether timeout is 0, which disables the timeout, or at least
+// minimumTimeoutSeconds, the shortest timeout tokens can be validated against.
+func ValidInactivityTimeout(timeout time.Duration, minimumTimeoutSeconds int32) bool {
+	// int32 will always round down to units, but that's ok
+	timeoutSeconds := int32(timeout.Seconds())
+	return timeoutSeconds == 0 || (timeoutSeconds > 0 && timeoutSeconds >= minimumTimeoutSeconds)
+}
+
 type TimeoutValidator struct {
 	oauthClients   oauthclientlister.OAuthClientLister
 	tokens         oauthclient.OAuthAccessTokenInterface
@@ -53,6 +95,7 @@ type TimeoutValidator struct {
 	data           *rankedset.RankedSet
 	defaultTimeout time.Duration
 	tickerInterval time.Duration
+	sessionPolicy  *SessionPolicyEvaluator
 
 	// fields that are used to have a deterministic order of events in unit tests
 	flushHandler    func(flushHorizon time.Time) // allows us to decorate this func during unit tests
@@ -60,7 +103,7 @@ type TimeoutValidator struct {
 	clock           clock.WithTicker             // allows us to control time during unit tests
 }
 
-func NewTimeoutValidator(tokens oauthclient.OAuthAccessTokenInterface, oauthClients oauthclientlister.OAuthClientLister, defaultTimeout time.Duration, minValidTimeout int32) *TimeoutValidator {
+func NewTimeoutValidator(tokens oauthclient.OAuthAccessTokenInterface, oauthClients oauthclientlister.OAuthClientLister, defaultTimeout time.Duration, minValidTimeout int32, sessionPolicy *SessionPolicyEvaluator) *TimeoutValidator {
 	a := &TimeoutValidator{
 		oauthClients:   oauthClients,
 		tokens:         tokens,
@@ -68,6 +111,7 @@ func NewTimeoutValidator(tokens oauthclient.OAuthAccessTokenInterface, oauthClie
 		data:           rankedset.New(),
 		defaultTimeout: defaultTimeout,
 		tickerInterval: timeoutAsDuration(minValidTimeout / 3), // we tick at least 3 times within each timeout period
+		sessionPolicy:  sessionPolicy,
 		clock:          clock.RealClock{},
 	}
 	a.flushHandler = a.flush
@@ -76,29 +120,48 @@ func NewTimeoutValidator(tokens oauthclient.OAuthAccessTokenInterface, oauthClie
 	return a
 }
 
-// Validate is called with a token when it is seen by an authenticator
-// it touches only the tokenChannel so it is safe to call from other threads
-func (a *TimeoutValidator) Validate(token *oauthv1.OAuthAccessToken, _ *userv1.User) error {
+// ValidateOnly checks whether the token timed out without recording that it was seen,
+// so that looking at a token does not extend its timeout
+func (a *TimeoutValidator) ValidateOnly(token *oauthv1.OAuthAccessToken, user *userv1.User) error {
 	if token.InactivityTimeoutSeconds == 0 {
-		// We care only if the token was created with a timeout to start with
 		return nil
 	}
+	td := &tokenData{token: token, user: user, seen: a.clock.Now()}
+	if td.timeout().Before(td.seen) {
+		return errTimedout
+	}
+	return nil
+}
+
+// Validate is called with a token when it is seen by an authenticator
+// it touches only the tokenChannel so it is safe to call from other threads
+func (a *TimeoutValidator) Validate(token *oauthv1.OAuthAccessToken, user *userv1.User) error {
+	return a.ValidateWithContext(context.Background(), token, user)
+}
 
+// ValidateWithContext is Validate for a token seen in a request, the span of the request is
+// linked from the span of the token update
+func (a *TimeoutValidator) ValidateWithContext(ctx context.Context, token *oauthv1.OAuthAccessToken, user *userv1.User) error {
 	td := &tokenData{
-		token: token,
-		seen:  a.clock.Now(),
+		token:       token,
+		user:        user,
+		seen:        a.clock.Now(),
+		requestSpan: trace.SpanFromContext(ctx),
+		// We care about the timeout only if the token was created with one to start with,
+		// and skip it if the timeout is already larger than expiration deadline
+		updateTimeout: token.InactivityTimeoutSeconds != 0 &&
+			(token.ExpiresIn == 0 || token.ExpiresIn > int64(token.InactivityTimeoutSeconds)),
 	}
-	if td.timeout().Before(td.seen) {
+	if token.InactivityTimeoutSeconds != 0 && td.timeout().Before(td.seen) {
 		return errTimedout
 	}
 
-	if token.ExpiresIn != 0 && token.ExpiresIn <= int64(token.InactivityTimeoutSeconds) {
-		// skip if the timeout is already larger than expiration deadline
+	if _, recordLastUsed := td.lastUsed(token); !td.updateTimeout && !recordLastUsed {
 		return nil
 	}
 	// After a positive timeout check we need to update the timeout and
 	// schedule an update so that we can either set or update the Timeout
-	// we do that launching a micro goroutine to avoid blocking
+	// and record the last use, we do that launching a micro goroutine to avoid blocking
 	go a.putTokenHandler(td)
 
 	return nil
@@ -120,31 +183,76 @@ func (a *TimeoutValidator) clientTimeout(name string) time.Duration {
 	return timeoutAsDuration(*oauthClient.AccessTokenInactivityTimeoutSeconds)
 }
 
-func (a *TimeoutValidator) update(td *tokenData) error {
-	// Obtain the timeout interval for this client
+// timeout returns the strictest of the client timeout and the session policy
+// timeout for the user of the token, but no less than the minimum timeout of
+// the session policy. The session policy can only shorten the timeout of tokens
+// that were created with one, tokens are not allowed to turn into timing out
+// tokens after issuance.
+func (a *TimeoutValidator) timeout(td *tokenData) time.Duration {
 	delta := a.clientTimeout(td.token.ClientName)
-	// if delta is 0 it means the OAuthClient has been changed to the
-	// no-timeout value. In this case we set newTimeout also to 0 so
-	// that the token will no longer timeout once updated.
-	newTimeout := int32(0)
-	if delta > 0 {
-		// InactivityTimeoutSeconds is the number of seconds since creation:
-		// InactivityTimeoutSeconds = Seen(Time) - CreationTimestamp(Time) + delta(Duration)
-		newTimeout = int32((td.seen.Sub(td.token.CreationTimestamp.Time) + delta) / time.Second)
+	limits, err := a.sessionPolicy.LimitsFor(td.user)
+	if err != nil {
+		klog.V(5).Infof("Failed to evaluate session policy for user=%q: %v", td.token.UserName, err)
+	} else {
+		delta = sessionpolicy.Strictest(delta, limits.InactivityTimeout)
+	}
+	if minimum := a.sessionPolicy.MinimumInactivityTimeout(); delta > 0 && delta < minimum {
+		return minimum
 	}
+	return delta
+}
+
+func (a *TimeoutValidator) update(td *tokenData) error {
+	// the update runs after the request the token was seen in has finished,
+	// so its span is linked rather than the parent of the update
+	ctx := context.TODO()
+	if td.requestSpan != nil {
+		var span trace.Span
+		ctx, span = td.requestSpan.TracerProvider().Tracer(tracerName).Start(ctx, "Update OAuth access token",
+			trace.WithLinks(trace.Link{SpanContext: td.requestSpan.SpanContext()}),
+			trace.WithAttributes(attribute.String("user", td.token.UserName), attribute.String("client", td.token.ClientName)))
+		defer span.End()
+	}
+
 	// We need to get the token again here because it may have changed in the
 	// DB and we need to verify it is still worth updating
-	token, err := a.tokens.Get(context.TODO(), td.token.Name, v1.GetOptions{})
+	token, err := a.tokens.Get(ctx, td.token.Name, v1.GetOptions{})
 	if err != nil {
 		return err
 	}
-	if newTimeout != 0 && token.InactivityTimeoutSeconds >= newTimeout {
+
+	changed := false
+	if td.updateTimeout {
+		// Obtain the timeout interval for this client and user
+		delta := a.timeout(td)
+		// if delta is 0 it means the OAuthClient has been changed to the
+		// no-timeout value. In this case we set newTimeout also to 0 so
+		// that the token will no longer timeout once updated.
+		newTimeout := int32(0)
+		if delta > 0 {
+			// InactivityTimeoutSeconds is the number of seconds since creation:
+			// InactivityTimeoutSeconds = Seen(Time) - CreationTimestamp(Time) + delta(Duration)
+			newTimeout = int32((td.seen.Sub(td.token.CreationTimestamp.Time) + delta) / time.Second)
+		}
 		// if the token was already updated with a higher or equal timeout we
 		// do not have anything to do
+		if newTimeout == 0 || token.InactivityTimeoutSeconds < newTimeout {
+			token.InactivityTimeoutSeconds = newTimeout
+			changed = true
+		}
+	}
+	if lastUsed, ok := td.lastUsed(token); ok {
+		if token.Annotations == nil {
+			token.Annotations = map[string]string{}
+		}
+		token.Annotations[oauthapi.LastUsedAnnotation] = lastUsed.Format(time.RFC3339)
+		changed = true
+	}
+
+	if !changed {
 		return nil
 	}
-	token.InactivityTimeoutSeconds = newTimeout
-	_, err = a.tokens.Update(context.TODO(), token, v1.UpdateOptions{})
+	_, err = a.tokens.Update(ctx, token, v1.UpdateOptions{})
 	return err
 }
 
@@ -219,8 +327,8 @@ func (a *TimeoutValidator) Run(stopCh <-chan struct{}) {
 		case td := <-a.tokenChannel:
 			a.data.Insert(td)
 			// if this token is going to time out before the timer, flush now
-			tokenTimeout := td.timeout()
-			if tokenTimeout.Before(nextTick) {
+			tokenTimeout := td.flushBy()
+			if td.updateTimeout && tokenTimeout.Before(nextTick) {
 				klog.V(5).Infof("Timeout for user=%q client=%q scopes=%v falls before next ticker (%s < %s), forcing flush!",
 					td.token.UserName, td.token.ClientName, td.token.Scopes, tokenTimeout, nextTick)
 				a.flushHandler(nextTick)
@@ -2,19 +2,24 @@ package tokenvalidation
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"errors"
 	"fmt"
-	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kauthenticator "k8s.io/apiserver/pkg/authentication/authenticator"
 	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/component-base/tracing"
 
 	authorizationv1 "github.com/openshift/api/authorization/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	userclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
 )
 
 var (
@@ -22,6 +27,12 @@ var (
 	errOldFormat = errors.New("old and insecure token format")
 )
 
+// authenticationTraceThreshold is how long an authentication may take before its trace is logged
+const authenticationTraceThreshold = 500 * time.Millisecond
+
+// tracerName is the instrumentation scope of the spans not started from a request
+const tracerName = "github.com/openshift/oauth-apiserver/pkg/tokenvalidation"
+
 type tokenAuthenticator struct {
 	tokens       oauthclient.OAuthAccessTokenInterface
 	users        userclient.UserInterface
@@ -40,38 +51,46 @@ func NewTokenAuthenticator(tokens oauthclient.OAuthAccessTokenInterface, users u
 	}
 }
 
-const sha256Prefix = "sha256~"
-
 func (a *tokenAuthenticator) AuthenticateToken(ctx context.Context, name string) (*kauthenticator.Response, bool, error) {
-	if !strings.HasPrefix(name, sha256Prefix) {
-		// only complain about non-sha256 format if the token is really an existing
-		// OAuthAccessToken (and e.g. no service account which also has no sha256 prefix)
+	ctx, span := tracing.Start(ctx, "OAuth token authentication")
+	defer span.End(authenticationTraceThreshold)
+
+	resp, ok, err := a.authenticateToken(ctx, name)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.AddEvent("Authenticated", attribute.Bool("authenticated", ok))
+	return resp, ok, err
+}
+
+func (a *tokenAuthenticator) authenticateToken(ctx context.Context, name string) (*kauthenticator.Response, bool, error) {
+	objectName, ok := tokenname.ObjectName(name)
+	if !ok {
+		// only complain about the old format if the token is really an existing
+		// OAuthAccessToken (and e.g. no service account which also has no hashing prefix)
 		_, err := a.tokens.Get(ctx, name, metav1.GetOptions{})
 		if err == nil {
 			return nil, false, errOldFormat
 		}
 		return nil, false, errLookup
 	}
+	name = objectName
 
-	withoutPrefix := strings.TrimPrefix(name, sha256Prefix)
-	h := sha256.Sum256([]byte(withoutPrefix))
-	name = sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
-
-	token, err := a.tokens.Get(ctx, name, metav1.GetOptions{})
+	token, err := a.getToken(ctx, name)
 	if err != nil {
 		return nil, false, errLookup // mask the error so we do not leak token data in logs
 	}
 
-	user, err := a.users.Get(ctx, token.UserName, metav1.GetOptions{})
+	user, err := a.getUser(ctx, token.UserName)
 	if err != nil {
 		return nil, false, err
 	}
 
-	if err := a.validators.Validate(token, user); err != nil {
+	if err := ValidateWithContext(ctx, a.validators, token, user); err != nil {
 		return nil, false, err
 	}
 
-	groups, err := a.groupMapper.GroupsFor(user.Name)
+	groups, err := a.groupsFor(ctx, user.Name)
 	if err != nil {
 		return nil, false, err
 	}
@@ -99,8 +118,28 @@ func (a *tokenAuthenticator) AuthenticateToken(ctx context.Context, name string)
 			Groups: groupNames,
 			Extra: map[string][]string{
 				authorizationv1.ScopesKey: token.Scopes,
+				kuser.CredentialIDKey:     {tokenname.CredentialID(token.Name)},
 			},
 		},
 		Audiences: auds,
 	}, true, nil
 }
+
+func (a *tokenAuthenticator) getToken(ctx context.Context, name string) (*oauthv1.OAuthAccessToken, error) {
+	// the name of the token is a credential, it is not recorded in the span
+	ctx, span := tracing.Start(ctx, "Get OAuth access token")
+	defer span.End(authenticationTraceThreshold)
+	return a.tokens.Get(ctx, name, metav1.GetOptions{})
+}
+
+func (a *tokenAuthenticator) getUser(ctx context.Context, name string) (*userv1.User, error) {
+	ctx, span := tracing.Start(ctx, "Get user", attribute.String("user", name))
+	defer span.End(authenticationTraceThreshold)
+	return a.users.Get(ctx, name, metav1.GetOptions{})
+}
+
+func (a *tokenAuthenticator) groupsFor(ctx context.Context, user string) ([]*userv1.Group, error) {
+	_, span := tracing.Start(ctx, "Map user to groups", attribute.String("user", user))
+	defer span.End(authenticationTraceThreshold)
+	return a.groupMapper.GroupsFor(user)
+}
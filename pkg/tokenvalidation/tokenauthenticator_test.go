@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,6 +21,10 @@ import (
 	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+
+	oauthapi "github.com/openshift/oauth-apiserver/pkg/oauth/apis/oauth"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/sessionpolicy"
+	"github.com/openshift/oauth-apiserver/pkg/tokenvalidation/tokenname"
 )
 
 func TestAuthenticateTokenInvalidUID(t *testing.T) {
@@ -152,7 +158,7 @@ func TestAuthenticateTokenTimeout(t *testing.T) {
 		clients: oauthClients,
 	}
 
-	timeouts := NewTimeoutValidator(accessTokenGetter, lister, timeoutAsDuration(defaultTimeout), minTimeout)
+	timeouts := NewTimeoutValidator(accessTokenGetter, lister, timeoutAsDuration(defaultTimeout), minTimeout, nil)
 
 	// inject fake clock, which has some interesting properties
 	// 1. A sleep will cause at most one ticker event, regardless of how long the sleep was
@@ -361,3 +367,202 @@ func wait(t *testing.T, c chan struct{}) {
 		t.Fatal("failed to see channel event")
 	}
 }
+
+func TestTimeoutValidatorSessionPolicy(t *testing.T) {
+	clientTimeout := int32(3600)
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthClient{ObjectMeta: metav1.ObjectMeta{Name: "client"}, AccessTokenInactivityTimeoutSeconds: &clientTimeout},
+	)
+	lister := &fakeOAuthClientLister{clients: fakeOAuthClient.OauthV1().OAuthClients()}
+	policy := &sessionpolicy.SessionPolicy{
+		MinimumInactivityTimeoutSeconds: sessionpolicy.DefaultMinimumInactivityTimeoutSeconds,
+		Rules: []sessionpolicy.Rule{
+			{Name: "admins", IdentityProviders: []string{"admin-idp"}, InactivityTimeoutSeconds: 600},
+		},
+	}
+	timeouts := NewTimeoutValidator(fakeOAuthClient.OauthV1().OAuthAccessTokens(), lister, 0, 300, NewSessionPolicyEvaluator(sessionpolicy.Static(policy), NoopGroupMapper{}))
+
+	for _, tc := range []struct {
+		identity string
+		want     time.Duration
+	}{
+		{identity: "admin-idp:foo", want: 10 * time.Minute},
+		{identity: "other-idp:foo", want: time.Hour},
+	} {
+		td := &tokenData{
+			token: &oauthv1.OAuthAccessToken{ClientName: "client", UserName: "foo"},
+			user:  &userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Identities: []string{tc.identity}},
+		}
+		if got := timeouts.timeout(td); got != tc.want {
+			t.Errorf("identity %s: expected timeout %v, got %v", tc.identity, tc.want, got)
+		}
+	}
+
+	// a raised minimum applies to the timeouts of the client and of the rules
+	policy.MinimumInactivityTimeoutSeconds = 1800
+	td := &tokenData{
+		token: &oauthv1.OAuthAccessToken{ClientName: "client", UserName: "foo"},
+		user:  &userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Identities: []string{"admin-idp:foo"}},
+	}
+	if got := timeouts.timeout(td); got != 30*time.Minute {
+		t.Errorf("expected the minimum timeout, got %v", got)
+	}
+}
+
+func TestTimeoutValidatorLastUsed(t *testing.T) {
+	testClock := clocktesting.NewFakeClock(time.Date(2020, 1, 1, 10, 0, 30, 0, time.UTC))
+	token := &oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: "sha256~token", CreationTimestamp: metav1.Time{Time: testClock.Now()}},
+		ClientName: "client",
+		UserName:   "foo",
+	}
+	fakeOAuthClient := oauthfake.NewSimpleClientset(token)
+	tokens := fakeOAuthClient.OauthV1().OAuthAccessTokens()
+	timeouts := NewTimeoutValidator(tokens, &fakeOAuthClientLister{clients: fakeOAuthClient.OauthV1().OAuthClients()}, 0, 300, nil)
+	timeouts.clock = testClock
+	queued := make(chan *tokenData, 1)
+	timeouts.putTokenHandler = func(td *tokenData) { queued <- td }
+
+	// a token without a timeout is still queued to record its use
+	if err := timeouts.Validate(token, &userv1.User{}); err != nil {
+		t.Fatal(err)
+	}
+	td := <-queued
+	if td.updateTimeout {
+		t.Error("expected a token without timeout to only record its last use")
+	}
+	if err := timeouts.update(td); err != nil {
+		t.Fatal(err)
+	}
+	token, err := tokens.Get(context.TODO(), token.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := token.Annotations[oauthapi.LastUsedAnnotation], "2020-01-01T10:00:00Z"; got != want {
+		t.Errorf("expected last used %q, got %q", want, got)
+	}
+	if token.InactivityTimeoutSeconds != 0 {
+		t.Errorf("expected no timeout, got %d", token.InactivityTimeoutSeconds)
+	}
+
+	// recent uses are not recorded again
+	testClock.Step(lastUsedUpdatePeriod - time.Minute)
+	if _, ok := (&tokenData{token: token, seen: testClock.Now()}).lastUsed(token); ok {
+		t.Error("expected a recent last use not to be updated")
+	}
+
+	testClock.Step(time.Minute)
+	if err := timeouts.Validate(token, &userv1.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := timeouts.update(<-queued); err != nil {
+		t.Fatal(err)
+	}
+	token, err = tokens.Get(context.TODO(), token.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := token.Annotations[oauthapi.LastUsedAnnotation], "2020-01-01T10:05:00Z"; got != want {
+		t.Errorf("expected last used %q, got %q", want, got)
+	}
+}
+
+func TestAuthenticateTokenSHA512(t *testing.T) {
+	tokenHash, ok := tokenname.ObjectName("sha512~someRandomTokenWhichIsLongEnough")
+	if !ok {
+		t.Fatal("expected sha512~ tokens to be supported")
+	}
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{
+			ObjectMeta: metav1.ObjectMeta{Name: tokenHash, CreationTimestamp: metav1.Time{Time: time.Now()}},
+			ExpiresIn:  600, // 10 minutes
+			UserName:   "foo",
+			UserUID:    string("bar"),
+		},
+	)
+	fakeUserClient := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}})
+
+	tokenAuthenticator := NewTokenAuthenticator(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(), NoopGroupMapper{}, nil, NewUIDValidator())
+
+	userInfo, found, err := tokenAuthenticator.AuthenticateToken(context.TODO(), "sha512~someRandomTokenWhichIsLongEnough")
+	if !found || err != nil {
+		t.Fatalf("expected the token to be found, got %v", err)
+	}
+	if userInfo.User.GetName() != "foo" {
+		t.Errorf("unexpected user %v", userInfo.User)
+	}
+}
+
+// spanCollector is a local collector stub that keeps the exported spans
+type spanCollector struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (c *spanCollector) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	c.spans = append(c.spans, spans...)
+	return nil
+}
+
+func (c *spanCollector) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestAuthenticateTokenTracing(t *testing.T) {
+	tokenClear, tokenHash := generateOAuthTokenPair()
+	fakeOAuthClient := oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{
+			ObjectMeta: metav1.ObjectMeta{Name: tokenHash, CreationTimestamp: metav1.Time{Time: time.Now()}},
+			ExpiresIn:  600, // 10 minutes
+			UserName:   "foo",
+			UserUID:    string("bar"),
+		},
+	)
+	fakeUserClient := userfake.NewSimpleClientset(&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "bar"}})
+
+	collector := &spanCollector{}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(collector))
+	// the span of the TokenReview request, propagated from the webhook by the server
+	ctx, requestSpan := tracerProvider.Tracer("test").Start(context.Background(), "TokenReview")
+
+	tokenAuthenticator := NewTokenAuthenticator(fakeOAuthClient.OauthV1().OAuthAccessTokens(), fakeUserClient.UserV1().Users(), NoopGroupMapper{}, nil,
+		NamedValidator(UserUIDValidatorName, NewUIDValidator()),
+		NamedValidator("Deny", OAuthTokenValidatorFunc(func(*oauthv1.OAuthAccessToken, *userv1.User) error { return errors.New("denied") })),
+	)
+	if _, found, _ := tokenAuthenticator.AuthenticateToken(ctx, tokenClear); found {
+		t.Fatal("expected the token to be denied")
+	}
+	requestSpan.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range collector.spans {
+		name := span.Name()
+		for _, attr := range span.Attributes() {
+			if attr.Key == "validator" {
+				name += " " + attr.Value.AsString()
+			}
+		}
+		spans[name] = span
+	}
+	for name, parent := range map[string]string{
+		"OAuth token authentication": "TokenReview",
+		"Get OAuth access token":     "OAuth token authentication",
+		"Get user":                   "OAuth token authentication",
+		"Validate token UserUID":     "OAuth token authentication",
+		"Validate token Deny":        "OAuth token authentication",
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("expected a %q span, got %v", name, collector.spans)
+			continue
+		}
+		if got, want := span.Parent().SpanID(), spans[parent].SpanContext().SpanID(); got != want {
+			t.Errorf("expected %q to be a child of %q", name, parent)
+		}
+	}
+	if _, ok := spans["Map user to groups"]; ok {
+		t.Error("expected no group mapping for a denied token")
+	}
+	if events := spans["Validate token Deny"].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("expected the denial to be recorded, got %v", events)
+	}
+}
@@ -0,0 +1,131 @@
+// Package tokenname maps OAuth tokens to the names of the objects that store them.
+// The name of a token object is a prefix naming the hashing scheme followed by the
+// URL-safe unpadded base64 encoding of the hashed token. The prefix is part of the
+// token itself, so the algorithm can be changed without breaking existing tokens.
+package tokenname
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	SHA256Prefix     = "sha256~"
+	SHA512Prefix     = "sha512~"
+	HMACSHA256Prefix = "hmac-sha256~"
+
+	// credentialIDPrefix marks credential IDs that name the object of an OAuth access token
+	credentialIDPrefix = "OAuthAccessToken="
+)
+
+// Scheme hashes the secret part of tokens with a given prefix.
+type Scheme interface {
+	// Prefix is the prefix of both the tokens and the names of their objects, including the "~"
+	Prefix() string
+	// Hash returns the hash of the token without its prefix
+	Hash(secret string) []byte
+}
+
+type hashScheme struct {
+	prefix  string
+	newHash func() hash.Hash
+}
+
+func (s hashScheme) Prefix() string {
+	return s.prefix
+}
+
+func (s hashScheme) Hash(secret string) []byte {
+	h := s.newHash()
+	h.Write([]byte(secret))
+	return h.Sum(nil)
+}
+
+// NewHMACSHA256Scheme returns a scheme that keys the token hashes with a server-side secret,
+// so that token names leaked from storage cannot be checked against guessed tokens.
+func NewHMACSHA256Scheme(key []byte) Scheme {
+	return hashScheme{
+		prefix:  HMACSHA256Prefix,
+		newHash: func() hash.Hash { return hmac.New(sha256.New, key) },
+	}
+}
+
+var (
+	lock    sync.RWMutex
+	schemes = map[string]Scheme{
+		SHA256Prefix: hashScheme{prefix: SHA256Prefix, newHash: sha256.New},
+		SHA512Prefix: hashScheme{prefix: SHA512Prefix, newHash: sha512.New},
+	}
+)
+
+// Register adds a scheme or replaces the scheme with the same prefix.
+// It is meant to be called during server startup.
+func Register(scheme Scheme) {
+	lock.Lock()
+	defer lock.Unlock()
+	schemes[scheme.Prefix()] = scheme
+}
+
+// Prefixes returns the sorted prefixes of all the supported schemes.
+func Prefixes() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	prefixes := make([]string, 0, len(schemes))
+	for prefix := range schemes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// HasSupportedPrefix is true if name starts with the prefix of a supported scheme.
+// It applies to both tokens and token object names.
+func HasSupportedPrefix(name string) bool {
+	_, _, ok := schemeFor(name)
+	return ok
+}
+
+// ObjectName returns the name of the object that stores token. It is false
+// if the token does not start with the prefix of a supported scheme.
+func ObjectName(token string) (string, bool) {
+	scheme, secret, ok := schemeFor(token)
+	if !ok {
+		return "", false
+	}
+	return scheme.Prefix() + base64.RawURLEncoding.EncodeToString(scheme.Hash(secret)), true
+}
+
+// CredentialID returns the credential ID of the users that authenticate with the token
+// stored in the named object.
+func CredentialID(objectName string) string {
+	return credentialIDPrefix + objectName
+}
+
+// ObjectNameFromCredentialID returns the name of the token object a credential ID refers to.
+// It is false for credentials other than OAuth access tokens.
+func ObjectNameFromCredentialID(credentialID string) (string, bool) {
+	objectName, ok := strings.CutPrefix(credentialID, credentialIDPrefix)
+	if !ok || !HasSupportedPrefix(objectName) {
+		return "", false
+	}
+	return objectName, true
+}
+
+func schemeFor(name string) (Scheme, string, bool) {
+	i := strings.Index(name, "~")
+	if i < 0 {
+		return nil, "", false
+	}
+
+	lock.RLock()
+	defer lock.RUnlock()
+	scheme, ok := schemes[name[:i+1]]
+	return scheme, name[i+1:], ok
+}
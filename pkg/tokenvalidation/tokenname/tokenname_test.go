@@ -0,0 +1,57 @@
+package tokenname
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func TestObjectName(t *testing.T) {
+	h := sha256.Sum256([]byte("token"))
+	sha256Name := SHA256Prefix + base64.RawURLEncoding.EncodeToString(h[:])
+
+	if name, ok := ObjectName("sha256~token"); !ok || name != sha256Name {
+		t.Errorf("expected %q, got %q, %v", sha256Name, name, ok)
+	}
+	if name, ok := ObjectName("sha512~token"); !ok || len(name) != len(SHA512Prefix)+86 {
+		t.Errorf("unexpected sha512 name %q, %v", name, ok)
+	}
+	for _, token := range []string{"token", "md5~token", "~token"} {
+		if name, ok := ObjectName(token); ok {
+			t.Errorf("expected %q to be rejected, got %q", token, name)
+		}
+	}
+}
+
+func TestRegisterHMAC(t *testing.T) {
+	if HasSupportedPrefix("hmac-sha256~token") {
+		t.Fatal("expected HMAC tokens to be rejected before a key is registered")
+	}
+
+	Register(NewHMACSHA256Scheme([]byte("key")))
+	defer func() {
+		lock.Lock()
+		defer lock.Unlock()
+		delete(schemes, HMACSHA256Prefix)
+	}()
+
+	withKey, ok := ObjectName("hmac-sha256~token")
+	if !ok {
+		t.Fatal("expected HMAC tokens to be supported")
+	}
+	Register(NewHMACSHA256Scheme([]byte("other")))
+	if withOtherKey, _ := ObjectName("hmac-sha256~token"); withOtherKey == withKey {
+		t.Error("expected the HMAC key to change the object name")
+	}
+}
+
+func TestCredentialID(t *testing.T) {
+	if name, ok := ObjectNameFromCredentialID(CredentialID("sha256~abc")); !ok || name != "sha256~abc" {
+		t.Errorf("expected sha256~abc, got %q, %v", name, ok)
+	}
+	for _, id := range []string{"", "sha256~abc", "OAuthAccessToken=", "OAuthAccessToken=md5~abc", "X509SHA256=abc"} {
+		if name, ok := ObjectNameFromCredentialID(id); ok {
+			t.Errorf("expected %q to be rejected, got %q", id, name)
+		}
+	}
+}